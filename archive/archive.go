@@ -0,0 +1,321 @@
+// Package archive creates and extracts the tar archives exchanged with
+// the daemon when copying files between the host and a container.
+package archive
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ExtractOptions holds the options to extract an archive.
+type ExtractOptions struct {
+	// NoOverwriteDirNonDir prevents an existing directory from being
+	// overwritten by a non-directory and vice versa.
+	NoOverwriteDirNonDir bool
+	// PreserveOwnership sets the uid and gid of the entries to the ones
+	// in the archive, instead of the ones of the current user.
+	PreserveOwnership bool
+}
+
+// TarResource archives the file or directory at the source path. The archive
+// has a single root entry named after the base of the path, or the entries of
+// the directory if the path ends with "/.".
+func TarResource(sourceInfo CopyInfo) (io.ReadCloser, error) {
+	return TarResourceRebase(sourceInfo.Path, sourceInfo.RebaseName)
+}
+
+// TarResourceRebase is like TarResource but renames the root entry
+// of the archive to the rebase name if it's not empty.
+func TarResourceRebase(sourcePath, rebaseName string) (io.ReadCloser, error) {
+	sourcePath = normalizePath(sourcePath)
+	if _, err := os.Lstat(sourcePath); err != nil {
+		// Catch the errors before the archive is streamed.
+		return nil, err
+	}
+
+	sourceDir, sourceBase := SplitPathDirEntry(sourcePath)
+	if rebaseName == "" {
+		rebaseName = sourceBase
+	}
+	root := filepath.Join(sourceDir, sourceBase)
+
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
+		err := filepath.Walk(root, func(filePath string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			relPath, err := filepath.Rel(root, filePath)
+			if err != nil {
+				return err
+			}
+			name := rebaseName
+			if relPath != "." {
+				name += "/" + filepath.ToSlash(relPath)
+			}
+			return addTarFile(tw, filePath, name, fi)
+		})
+		if err == nil {
+			err = tw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+// FileInfoHeader returns the header of the archive entry named name
+// for the file at the path, with the target of a symbolic link.
+func FileInfoHeader(filePath, name string, fi os.FileInfo) (*tar.Header, error) {
+	var link string
+	if fi.Mode()&os.ModeSymlink != 0 {
+		var err error
+		if link, err = os.Readlink(filePath); err != nil {
+			return nil, err
+		}
+	}
+	hdr, err := tar.FileInfoHeader(fi, link)
+	if err != nil {
+		return nil, err
+	}
+	hdr.Name = name
+	if fi.IsDir() && !strings.HasSuffix(name, "/") {
+		hdr.Name += "/"
+	}
+	return hdr, nil
+}
+
+// WriteTarFile writes the header to the archive, followed
+// by the content of the file at the path if it's a regular file.
+func WriteTarFile(tw *tar.Writer, filePath string, hdr *tar.Header) error {
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if hdr.Typeflag != tar.TypeReg {
+		return nil
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(tw, file)
+	return err
+}
+
+// addTarFile writes the header and the content of a file to the archive.
+// Sockets can't be archived and are skipped.
+func addTarFile(tw *tar.Writer, filePath, name string, fi os.FileInfo) error {
+	if fi.Mode()&os.ModeSocket != 0 {
+		return nil
+	}
+	hdr, err := FileInfoHeader(filePath, name, fi)
+	if err != nil {
+		return err
+	}
+	return WriteTarFile(tw, filePath, hdr)
+}
+
+// RebaseArchiveEntries renames the entries of the archive whose first path
+// component is the old base, so that it becomes the new base.
+func RebaseArchiveEntries(content io.Reader, oldBase, newBase string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		tr := tar.NewReader(content)
+		tw := tar.NewWriter(pw)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				pw.CloseWithError(tw.Close())
+				return
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+
+			hdr.Name = rebaseName(hdr.Name, oldBase, newBase)
+			if hdr.Typeflag == tar.TypeLink {
+				// Hard links are relative to the root of the archive.
+				hdr.Linkname = rebaseName(hdr.Linkname, oldBase, newBase)
+			}
+			if err := tw.WriteHeader(hdr); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if _, err := io.Copy(tw, tr); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return pr
+}
+
+func rebaseName(name, oldBase, newBase string) string {
+	if name == oldBase || strings.HasPrefix(name, oldBase+"/") {
+		return newBase + strings.TrimPrefix(name, oldBase)
+	}
+	return name
+}
+
+// Untar extracts the archive to the destination directory, which must exist.
+// Entries can't be extracted outside of the directory, nor through a symbolic link.
+func Untar(content io.Reader, dstDir string, options ExtractOptions) error {
+	dstDir = filepath.Clean(normalizePath(dstDir))
+	fi, err := os.Stat(dstDir)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return ErrNotDirectory
+	}
+
+	// The modes and times of the directories are set once their content
+	// is extracted, in case they are read-only.
+	var dirs []*tar.Header
+	tr := tar.NewReader(content)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if name == ".." || strings.HasPrefix(name, ".."+string(os.PathSeparator)) || filepath.IsAbs(name) {
+			return fmt.Errorf("invalid archive entry %q outside of the destination", hdr.Name)
+		}
+		if err := checkNoSymlink(dstDir, filepath.Dir(name)); err != nil {
+			return err
+		}
+		path := filepath.Join(dstDir, name)
+
+		if fi, err := os.Lstat(path); err == nil {
+			isDir := hdr.Typeflag == tar.TypeDir
+			if options.NoOverwriteDirNonDir && fi.IsDir() && !isDir {
+				return fmt.Errorf("cannot overwrite directory %q with non-directory %q", path, hdr.Name)
+			}
+			if options.NoOverwriteDirNonDir && !fi.IsDir() && isDir {
+				return fmt.Errorf("cannot overwrite non-directory %q with directory %q", path, hdr.Name)
+			}
+			if name == "." && fi.IsDir() {
+				// The destination directory is the root of the archive.
+				continue
+			}
+			if !fi.IsDir() || !isDir {
+				if err := os.RemoveAll(path); err != nil {
+					return err
+				}
+			}
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+
+		if parent := filepath.Dir(path); parent != dstDir {
+			if err := os.MkdirAll(parent, 0755); err != nil {
+				return err
+			}
+		}
+		if err := extractTarEntry(tr, hdr, dstDir, path); err != nil {
+			return err
+		}
+		if options.PreserveOwnership {
+			if err := os.Lchown(path, hdr.Uid, hdr.Gid); err != nil {
+				return err
+			}
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			dirs = append(dirs, hdr)
+			continue
+		}
+		if hdr.Typeflag != tar.TypeSymlink {
+			if err := setModeAndTime(path, hdr); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		path := filepath.Join(dstDir, filepath.FromSlash(dirs[i].Name))
+		if err := setModeAndTime(path, dirs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractTarEntry(tr *tar.Reader, hdr *tar.Header, dstDir, path string) error {
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		if _, err := os.Lstat(path); err == nil {
+			return nil
+		}
+		return os.Mkdir(path, 0700)
+	case tar.TypeReg, tar.TypeRegA:
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(file, tr); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	case tar.TypeSymlink:
+		return os.Symlink(hdr.Linkname, path)
+	case tar.TypeLink:
+		target := filepath.Clean(filepath.FromSlash(hdr.Linkname))
+		if target == ".." || strings.HasPrefix(target, ".."+string(os.PathSeparator)) || filepath.IsAbs(target) {
+			return fmt.Errorf("invalid hard link %q outside of the destination", hdr.Linkname)
+		}
+		// The link would target a file outside of the destination
+		// through a symbolic link extracted earlier.
+		if err := checkNoSymlink(dstDir, target); err != nil {
+			return err
+		}
+		return os.Link(filepath.Join(dstDir, target), path)
+	}
+	return fmt.Errorf("unsupported type %q of archive entry %q", hdr.Typeflag, hdr.Name)
+}
+
+func setModeAndTime(path string, hdr *tar.Header) error {
+	if err := os.Chmod(path, hdr.FileInfo().Mode()); err != nil {
+		return err
+	}
+	if hdr.ModTime.IsZero() {
+		return nil
+	}
+	return os.Chtimes(path, time.Now(), hdr.ModTime)
+}
+
+// checkNoSymlink returns an error if one of the components of the
+// relative path is a symbolic link, which could lead outside of the root.
+func checkNoSymlink(root, relPath string) error {
+	if relPath == "." {
+		return nil
+	}
+	path := root
+	for _, component := range strings.Split(relPath, string(os.PathSeparator)) {
+		path = filepath.Join(path, component)
+		fi, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("cannot extract through the symbolic link %q", path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestArchive(t *testing.T, headers ...*tar.Header) *bytes.Buffer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, hdr := range headers {
+		if hdr.Typeflag == tar.TypeReg {
+			hdr.Size = int64(len(hdr.Name))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			tw.Write([]byte(hdr.Name))
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestRebaseArchiveEntries(t *testing.T) {
+	content := writeTestArchive(t,
+		&tar.Header{Name: "old/", Typeflag: tar.TypeDir, Mode: 0755},
+		&tar.Header{Name: "old/file", Typeflag: tar.TypeReg, Mode: 0644},
+		&tar.Header{Name: "old/link", Typeflag: tar.TypeLink, Linkname: "old/file"},
+		&tar.Header{Name: "older", Typeflag: tar.TypeReg, Mode: 0644},
+	)
+	tr := tar.NewReader(RebaseArchiveEntries(content, "old", "new"))
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, hdr.Name+":"+hdr.Linkname)
+	}
+	expected := "new/: new/file: new/link:new/file older:"
+	if strings.Join(names, " ") != expected {
+		t.Fatalf("expected the entries %s, got %s", expected, strings.Join(names, " "))
+	}
+}
+
+func TestUntarOutsideOfDestination(t *testing.T) {
+	cases := []struct {
+		headers       []*tar.Header
+		expectedError string
+	}{
+		{
+			headers:       []*tar.Header{{Name: "../file", Typeflag: tar.TypeReg}},
+			expectedError: "invalid archive entry",
+		},
+		{
+			headers:       []*tar.Header{{Name: "/file", Typeflag: tar.TypeReg}},
+			expectedError: "invalid archive entry",
+		},
+		{
+			headers:       []*tar.Header{{Name: "link", Typeflag: tar.TypeLink, Linkname: "../file"}},
+			expectedError: "invalid hard link",
+		},
+		{
+			headers: []*tar.Header{
+				{Name: "symlink", Typeflag: tar.TypeSymlink, Linkname: "/"},
+				{Name: "symlink/file", Typeflag: tar.TypeReg},
+			},
+			expectedError: "cannot extract through the symbolic link",
+		},
+		{
+			headers: []*tar.Header{
+				{Name: "symlink", Typeflag: tar.TypeSymlink, Linkname: "/etc"},
+				{Name: "link", Typeflag: tar.TypeLink, Linkname: "symlink/passwd"},
+			},
+			expectedError: "cannot extract through the symbolic link",
+		},
+	}
+	for _, c := range cases {
+		dir, err := ioutil.TempDir("", "archive-untar")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = Untar(writeTestArchive(t, c.headers...), dir, ExtractOptions{})
+		os.RemoveAll(dir)
+		if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+			t.Fatalf("expected an error containing %q, got %v", c.expectedError, err)
+		}
+	}
+}
+
+func TestUntarNoOverwriteDirNonDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive-untar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Mkdir(filepath.Join(dir, "dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	content := writeTestArchive(t, &tar.Header{Name: "dir", Typeflag: tar.TypeReg, Mode: 0644})
+	err = Untar(content, dir, ExtractOptions{NoOverwriteDirNonDir: true})
+	if err == nil || !strings.Contains(err.Error(), "cannot overwrite directory") {
+		t.Fatalf("expected the directory not to be overwritten, got %v", err)
+	}
+
+	content = writeTestArchive(t, &tar.Header{Name: "dir", Typeflag: tar.TypeReg, Mode: 0644})
+	if err := Untar(content, dir, ExtractOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Lstat(filepath.Join(dir, "dir")); err != nil || !fi.Mode().IsRegular() {
+		t.Fatalf("expected the directory to be replaced by a file, got %v, %v", fi, err)
+	}
+}
@@ -0,0 +1,213 @@
+package archive
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Errors used or returned by this file.
+var (
+	ErrNotDirectory  = errors.New("not a directory")
+	ErrDirNotExists  = errors.New("no such directory")
+	ErrCannotCopyDir = errors.New("cannot copy directory")
+)
+
+// maxSymlinks is the number of symbolic links followed
+// to resolve a path before giving up.
+const maxSymlinks = 10
+
+// CopyInfo holds basic info about the source
+// or destination path of a copy operation.
+type CopyInfo struct {
+	Path   string
+	Exists bool
+	IsDir  bool
+	// RebaseName is the name the source is copied as,
+	// when it's not the base of the path.
+	RebaseName string
+}
+
+// normalizePath converts the separators of the path to the ones of the OS,
+// keeping the trailing separators and dots that change the copy semantics.
+func normalizePath(path string) string {
+	return filepath.FromSlash(path)
+}
+
+// hasTrailingPathSeparator returns whether the path ends with a separator.
+func hasTrailingPathSeparator(path string) bool {
+	return len(path) > 0 && os.IsPathSeparator(path[len(path)-1])
+}
+
+// specifiesCurrentDir returns whether the base of the path is ".",
+// which means that the content of the directory is copied instead
+// of the directory itself.
+func specifiesCurrentDir(path string) bool {
+	return filepath.Base(path) == "."
+}
+
+// assertsDirectory returns whether the path must be a directory.
+func assertsDirectory(path string) bool {
+	return hasTrailingPathSeparator(path) || specifiesCurrentDir(path)
+}
+
+// SplitPathDirEntry splits the path into its parent directory and its base,
+// keeping the "." of a path that specifies the content of a directory.
+func SplitPathDirEntry(path string) (dir, base string) {
+	cleanedPath := filepath.Clean(normalizePath(path))
+	if specifiesCurrentDir(path) {
+		cleanedPath += string(os.PathSeparator) + "."
+	}
+	return filepath.Dir(cleanedPath), filepath.Base(cleanedPath)
+}
+
+// GetRebaseName returns the resolved path of a symbolic link, with the
+// trailing separator or dot of the original path, and the name the
+// resolved path must be copied as if its base is different.
+func GetRebaseName(path, resolvedPath string) (string, string) {
+	if specifiesCurrentDir(path) && !specifiesCurrentDir(resolvedPath) {
+		resolvedPath += string(filepath.Separator) + "."
+	}
+	if hasTrailingPathSeparator(path) && !hasTrailingPathSeparator(resolvedPath) {
+		resolvedPath += string(filepath.Separator)
+	}
+
+	var rebaseName string
+	if filepath.Base(path) != filepath.Base(resolvedPath) {
+		// The base of the resolved path is renamed to the one of the link.
+		_, rebaseName = SplitPathDirEntry(path)
+	}
+	return resolvedPath, rebaseName
+}
+
+// CopyInfoSourcePath stats the source of a copy on the host. A symbolic
+// link is copied as is, unless followLink is set or the path ends with a
+// separator or "/.", in which case its target is copied under the name
+// of the link.
+func CopyInfoSourcePath(path string, followLink bool) (CopyInfo, error) {
+	path = normalizePath(path)
+
+	resolvedPath := path
+	var rebaseName string
+	if followLink || assertsDirectory(path) {
+		dir, base := SplitPathDirEntry(path)
+		target, err := filepath.EvalSymlinks(filepath.Join(dir, base))
+		if err != nil {
+			return CopyInfo{}, err
+		}
+		resolvedPath, rebaseName = GetRebaseName(path, target)
+	}
+
+	stat, err := os.Lstat(resolvedPath)
+	if err != nil {
+		return CopyInfo{}, err
+	}
+	if assertsDirectory(path) && !stat.IsDir() {
+		return CopyInfo{}, ErrNotDirectory
+	}
+	return CopyInfo{
+		Path:       resolvedPath,
+		Exists:     true,
+		IsDir:      stat.IsDir(),
+		RebaseName: rebaseName,
+	}, nil
+}
+
+// CopyInfoDestinationPath stats the destination of a copy on the host,
+// following the symbolic links. A destination that doesn't exist is
+// valid as long as its parent directory exists.
+func CopyInfoDestinationPath(path string) (CopyInfo, error) {
+	path = normalizePath(path)
+	originalPath := path
+
+	stat, err := os.Lstat(path)
+	for n := 0; err == nil && stat.Mode()&os.ModeSymlink != 0; n++ {
+		if n >= maxSymlinks {
+			return CopyInfo{}, errors.New("too many symbolic links in " + originalPath)
+		}
+		var linkTarget string
+		if linkTarget, err = os.Readlink(path); err != nil {
+			return CopyInfo{}, err
+		}
+		if !filepath.IsAbs(linkTarget) {
+			dir, _ := SplitPathDirEntry(path)
+			linkTarget = filepath.Join(dir, linkTarget)
+		}
+		path = linkTarget
+		stat, err = os.Lstat(path)
+	}
+
+	if err == nil {
+		return CopyInfo{Path: path, Exists: true, IsDir: stat.IsDir()}, nil
+	}
+	if !os.IsNotExist(err) {
+		return CopyInfo{}, err
+	}
+	dir, _ := SplitPathDirEntry(path)
+	parentStat, err := os.Stat(dir)
+	if err != nil {
+		return CopyInfo{}, err
+	}
+	if !parentStat.IsDir() {
+		return CopyInfo{}, ErrNotDirectory
+	}
+	return CopyInfo{Path: path}, nil
+}
+
+// PrepareArchiveCopy returns the directory the archive of the source must be
+// extracted to, and the archive with its entries renamed to match the
+// destination, following the semantics of `docker cp`:
+//
+//   - a source is copied into a destination directory that exists,
+//   - a source directory can't replace a destination file,
+//   - a source file replaces a destination file,
+//   - a source that doesn't end with "/." is copied as the destination when it
+//     doesn't exist, as long as it's a directory if the destination path ends
+//     with a separator.
+func PrepareArchiveCopy(content io.Reader, srcInfo, dstInfo CopyInfo) (dstDir string, archive io.ReadCloser, err error) {
+	_, srcBase := SplitPathDirEntry(srcInfo.Path)
+	if srcInfo.RebaseName != "" {
+		srcBase = srcInfo.RebaseName
+	}
+	dstDir, dstBase := SplitPathDirEntry(dstInfo.Path)
+
+	switch {
+	case dstInfo.Exists && dstInfo.IsDir:
+		// The entries of the archive are extracted in the directory as is.
+		return dstInfo.Path, ioutil.NopCloser(content), nil
+	case dstInfo.Exists && srcInfo.IsDir:
+		return "", nil, ErrCannotCopyDir
+	case dstInfo.Exists:
+		// The source file is renamed to replace the destination file.
+		return dstDir, RebaseArchiveEntries(content, srcBase, dstBase), nil
+	case srcInfo.IsDir:
+		// The source directory, or its content, is extracted in the parent
+		// of the destination, as a directory named after the destination.
+		return dstDir, RebaseArchiveEntries(content, srcBase, dstBase), nil
+	case assertsDirectory(dstInfo.Path):
+		// A single file can't create a directory.
+		return "", nil, ErrDirNotExists
+	default:
+		return dstDir, RebaseArchiveEntries(content, srcBase, dstBase), nil
+	}
+}
+
+// CopyTo extracts the archive of the source to the destination path on the host.
+func CopyTo(content io.Reader, srcInfo CopyInfo, dstPath string, options ExtractOptions) error {
+	dstInfo, err := CopyInfoDestinationPath(dstPath)
+	if err != nil {
+		return err
+	}
+	if dstInfo.Exists && !dstInfo.IsDir && hasTrailingPathSeparator(dstPath) {
+		return ErrNotDirectory
+	}
+
+	dstDir, archive, err := PrepareArchiveCopy(content, srcInfo, dstInfo)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+	return Untar(archive, dstDir, options)
+}
@@ -0,0 +1,120 @@
+package archive
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitPathDirEntry(t *testing.T) {
+	cases := []struct {
+		path, dir, base string
+	}{
+		{"file", ".", "file"},
+		{"dir/file", "dir", "file"},
+		{"dir/sub/", "dir", "sub"},
+		{"dir/.", "dir", "."},
+		{"dir/./", "dir", "."},
+		{"/", "/", "/"},
+	}
+	for _, c := range cases {
+		dir, base := SplitPathDirEntry(c.path)
+		if dir != filepath.FromSlash(c.dir) || base != filepath.FromSlash(c.base) {
+			t.Fatalf("expected %s to be split into %s and %s, got %s and %s", c.path, c.dir, c.base, dir, base)
+		}
+	}
+}
+
+func TestGetRebaseName(t *testing.T) {
+	cases := []struct {
+		path, resolvedPath, expectedPath, expectedRebaseName string
+	}{
+		{"link", "target", "target", "link"},
+		{"link/", "target", "target/", "link"},
+		{"link/.", "target", "target/.", ""},
+		{"dir/link", "other/link", "other/link", ""},
+	}
+	for _, c := range cases {
+		path, rebaseName := GetRebaseName(filepath.FromSlash(c.path), filepath.FromSlash(c.resolvedPath))
+		if path != filepath.FromSlash(c.expectedPath) || rebaseName != c.expectedRebaseName {
+			t.Fatalf("expected %s resolved as %s to be %s renamed %q, got %s renamed %q", c.path, c.resolvedPath, c.expectedPath, c.expectedRebaseName, path, rebaseName)
+		}
+	}
+}
+
+func TestCopyTo(t *testing.T) {
+	srcDir, err := ioutil.TempDir("", "archive-src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(srcDir)
+	dstDir, err := ioutil.TempDir("", "archive-dst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dstDir)
+	if err := ioutil.WriteFile(filepath.Join(srcDir, "file"), []byte("content"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("file", filepath.Join(srcDir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	srcInfo, err := CopyInfoSourcePath(filepath.Join(srcDir, "link"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srcInfo.Path != filepath.Join(srcDir, "file") || srcInfo.RebaseName != "link" || srcInfo.IsDir {
+		t.Fatalf("expected the link to be resolved, got %+v", srcInfo)
+	}
+	content, err := TarResource(srcInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer content.Close()
+	if err := CopyTo(content, srcInfo, filepath.Join(dstDir, "copy"), ExtractOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	copied, err := ioutil.ReadFile(filepath.Join(dstDir, "copy"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(copied) != "content" {
+		t.Fatalf("expected the content of the file to be copied, got %q", copied)
+	}
+	if fi, err := os.Lstat(filepath.Join(dstDir, "copy")); err != nil || fi.Mode() != 0600 {
+		t.Fatalf("expected the mode of the file to be preserved, got %v, %v", fi, err)
+	}
+}
+
+func TestPrepareArchiveCopyErrors(t *testing.T) {
+	cases := []struct {
+		srcInfo, dstInfo CopyInfo
+		expectedError    error
+	}{
+		{
+			srcInfo:       CopyInfo{Path: "dir", Exists: true, IsDir: true},
+			dstInfo:       CopyInfo{Path: "file", Exists: true},
+			expectedError: ErrCannotCopyDir,
+		},
+		{
+			srcInfo:       CopyInfo{Path: "file", Exists: true},
+			dstInfo:       CopyInfo{Path: "dir/"},
+			expectedError: ErrDirNotExists,
+		},
+		{
+			srcInfo:       CopyInfo{Path: "file", Exists: true},
+			dstInfo:       CopyInfo{Path: "dir/."},
+			expectedError: ErrDirNotExists,
+		},
+	}
+	for _, c := range cases {
+		_, _, err := PrepareArchiveCopy(bytes.NewReader(nil), c.srcInfo, c.dstInfo)
+		if err != c.expectedError {
+			t.Fatalf("expected %v copying %+v to %+v, got %v", c.expectedError, c.srcInfo, c.dstInfo, err)
+		}
+	}
+}
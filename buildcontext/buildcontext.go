@@ -0,0 +1,332 @@
+// Package buildcontext creates the build contexts sent to the daemon to build
+// images, with the same semantics as the docker CLI.
+//
+// The files of the context directory are archived, except the ones excluded
+// by the patterns of its .dockerignore file. The Dockerfile and the
+// .dockerignore file are always sent, so that the daemon can read them.
+package buildcontext
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/engine-api/archive"
+)
+
+// DefaultDockerfileName is the name of the Dockerfile
+// looked up in the context directory by default.
+const DefaultDockerfileName = "Dockerfile"
+
+// dockerignoreName is the name of the file
+// with the patterns of the excluded files.
+const dockerignoreName = ".dockerignore"
+
+// Options holds the options to create a build context.
+type Options struct {
+	// Dockerfile is the path of the Dockerfile, relative to the current
+	// directory. It defaults to the Dockerfile in the context directory,
+	// and can be outside of it.
+	Dockerfile string
+	// Compress compresses the context with gzip.
+	Compress bool
+}
+
+// Context is the tar stream of a build context. It's up to the caller to close it.
+type Context struct {
+	io.ReadCloser
+	// Dockerfile is the path of the Dockerfile in the context,
+	// to set as ImageBuildOptions.Dockerfile.
+	Dockerfile string
+	// Size is the total size in bytes of the files in the context,
+	// before they're archived.
+	Size int64
+	// Files is the number of files in the context, including the
+	// symbolic links but not the directories.
+	Files int
+}
+
+// contextFile is a file of the context directory to archive.
+type contextFile struct {
+	path string
+	name string
+	info os.FileInfo
+}
+
+// Create archives the context directory. The files are walked before the
+// context is returned, to report its size and to check that they can be
+// read, but they're only archived as the context is read.
+func Create(contextDir string, options Options) (*Context, error) {
+	contextDir, dockerfile, err := resolvePaths(contextDir, options.Dockerfile)
+	if err != nil {
+		return nil, err
+	}
+
+	excludes, err := readDockerignoreFile(contextDir)
+	if err != nil {
+		return nil, err
+	}
+	// The Dockerfile and the .dockerignore file are sent to the daemon
+	// even if they are excluded.
+	relDockerfile, err := filepath.Rel(contextDir, dockerfile)
+	if err != nil {
+		return nil, err
+	}
+	outside := relDockerfile == ".." || strings.HasPrefix(relDockerfile, ".."+string(filepath.Separator))
+	excludes = append(excludes, "!"+dockerignoreName)
+	if !outside {
+		excludes = append(excludes, "!"+filepath.ToSlash(relDockerfile))
+	}
+	pm, err := newPatternMatcher(excludes)
+	if err != nil {
+		return nil, fmt.Errorf("error checking context: %v", err)
+	}
+
+	files, err := walkContext(contextDir, pm)
+	if err != nil {
+		return nil, err
+	}
+
+	buildContext := &Context{Dockerfile: filepath.ToSlash(relDockerfile)}
+	var dockerfileContent []byte
+	if outside {
+		// The Dockerfile is added to the context under a random name,
+		// which is excluded from the files that can be added to the image.
+		if dockerfileContent, err = ioutil.ReadFile(dockerfile); err != nil {
+			return nil, err
+		}
+		if buildContext.Dockerfile, err = randomDockerfileName(); err != nil {
+			return nil, err
+		}
+		buildContext.Size += int64(len(dockerfileContent))
+		buildContext.Files++
+		if !hasFile(files, dockerignoreName) {
+			buildContext.Files++
+		}
+	}
+	for _, f := range files {
+		if !f.info.IsDir() {
+			buildContext.Files++
+		}
+		if f.info.Mode().IsRegular() {
+			buildContext.Size += f.info.Size()
+		}
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeContext(pw, files, buildContext.Dockerfile, dockerfileContent, options.Compress))
+	}()
+	buildContext.ReadCloser = pr
+	return buildContext, nil
+}
+
+// resolvePaths returns the absolute paths of the context directory
+// and of the Dockerfile, with their symbolic links evaluated.
+func resolvePaths(contextDir, dockerfile string) (string, string, error) {
+	absContextDir, err := filepath.Abs(contextDir)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to get absolute context directory of given context directory %q: %v", contextDir, err)
+	}
+	absContextDir, err = filepath.EvalSymlinks(absContextDir)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to evaluate symlinks in context path: %v", err)
+	}
+	stat, err := os.Lstat(absContextDir)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to stat context directory %q: %v", absContextDir, err)
+	}
+	if !stat.IsDir() {
+		return "", "", fmt.Errorf("context must be a directory: %s", absContextDir)
+	}
+
+	absDockerfile := dockerfile
+	if absDockerfile == "" {
+		absDockerfile = filepath.Join(absContextDir, DefaultDockerfileName)
+		if _, err := os.Lstat(absDockerfile); os.IsNotExist(err) {
+			// The lowercase name is accepted too.
+			altDockerfile := filepath.Join(absContextDir, strings.ToLower(DefaultDockerfileName))
+			if _, err := os.Lstat(altDockerfile); err == nil {
+				absDockerfile = altDockerfile
+			}
+		}
+	}
+	if !filepath.IsAbs(absDockerfile) {
+		if absDockerfile, err = filepath.Abs(absDockerfile); err != nil {
+			return "", "", fmt.Errorf("unable to get absolute path to Dockerfile: %v", err)
+		}
+	}
+	absDockerfile, err = filepath.EvalSymlinks(absDockerfile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", fmt.Errorf("cannot locate specified Dockerfile: %s", dockerfile)
+		}
+		return "", "", fmt.Errorf("unable to evaluate symlinks in Dockerfile path: %v", err)
+	}
+	stat, err = os.Stat(absDockerfile)
+	if err != nil {
+		return "", "", err
+	}
+	if stat.IsDir() {
+		return "", "", fmt.Errorf("the Dockerfile %s is a directory", absDockerfile)
+	}
+	return absContextDir, absDockerfile, nil
+}
+
+// readDockerignoreFile returns the patterns of the .dockerignore file
+// of the context directory, if any.
+func readDockerignoreFile(contextDir string) ([]string, error) {
+	f, err := os.Open(filepath.Join(contextDir, dockerignoreName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	excludes, err := ReadDockerignore(f)
+	if err != nil {
+		return nil, fmt.Errorf("error reading .dockerignore: %v", err)
+	}
+	return excludes, nil
+}
+
+// walkContext returns the files of the context directory that aren't
+// excluded, and checks that they can be read.
+func walkContext(contextDir string, pm *patternMatcher) ([]contextFile, error) {
+	var files []contextFile
+	err := filepath.Walk(contextDir, func(filePath string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsPermission(err) {
+				return fmt.Errorf("can't stat '%s'", filePath)
+			}
+			return err
+		}
+		relPath, err := filepath.Rel(contextDir, filePath)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+
+		if pm.matches(relPath) {
+			// The content of an excluded directory is walked only if
+			// an exception may include some of its files.
+			if fi.IsDir() && !pm.mayIncludeChildren(relPath) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if fi.Mode()&os.ModeSocket != 0 {
+			return nil
+		}
+		if fi.Mode().IsRegular() {
+			f, err := os.Open(filePath)
+			if err != nil {
+				if os.IsPermission(err) {
+					return fmt.Errorf("no permission to read from '%s'", filePath)
+				}
+				return err
+			}
+			f.Close()
+		}
+		files = append(files, contextFile{path: filePath, name: filepath.ToSlash(relPath), info: fi})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error checking context: %v", err)
+	}
+	return files, nil
+}
+
+// writeContext writes the archive of the files, owned by root, followed by
+// the content of a Dockerfile outside of the context if it's not nil.
+func writeContext(w io.Writer, files []contextFile, dockerfileName string, dockerfileContent []byte, compress bool) error {
+	var gw *gzip.Writer
+	if compress {
+		gw = gzip.NewWriter(w)
+		w = gw
+	}
+	tw := tar.NewWriter(w)
+
+	for _, f := range files {
+		hdr, err := archive.FileInfoHeader(f.path, f.name, f.info)
+		if err != nil {
+			return err
+		}
+		hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname = 0, 0, "", ""
+
+		if f.name == dockerignoreName && dockerfileContent != nil {
+			// The daemon must exclude the Dockerfile too.
+			content, err := ioutil.ReadFile(f.path)
+			if err != nil {
+				return err
+			}
+			content = append(content, []byte("\n"+dockerfileName+"\n")...)
+			if err := writeTarContent(tw, hdr, content); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := archive.WriteTarFile(tw, f.path, hdr); err != nil {
+			return err
+		}
+	}
+
+	if dockerfileContent != nil {
+		hdr := &tar.Header{Name: dockerfileName, Mode: 0600, Typeflag: tar.TypeReg}
+		if err := writeTarContent(tw, hdr, dockerfileContent); err != nil {
+			return err
+		}
+		if !hasFile(files, dockerignoreName) {
+			hdr := &tar.Header{Name: dockerignoreName, Mode: 0600, Typeflag: tar.TypeReg}
+			if err := writeTarContent(tw, hdr, []byte(dockerfileName+"\n")); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if gw != nil {
+		return gw.Close()
+	}
+	return nil
+}
+
+func hasFile(files []contextFile, name string) bool {
+	for _, f := range files {
+		if f.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func writeTarContent(tw *tar.Writer, hdr *tar.Header, content []byte) error {
+	hdr.Size = int64(len(content))
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(content)
+	return err
+}
+
+// randomDockerfileName returns a name for a Dockerfile outside of
+// the context that doesn't conflict with the files of the context.
+func randomDockerfileName() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return ".dockerfile." + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,177 @@
+package buildcontext
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// createContextDir creates a context directory with the files,
+// whose content is their name.
+func createContextDir(t *testing.T, files ...string) string {
+	dir, err := ioutil.TempDir("", "buildcontext")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// readContext returns the content of the files in the
+// archive by their name, or "dir" for the directories.
+func readContext(t *testing.T, r io.Reader) map[string]string {
+	entries := map[string]string{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Uid != 0 || hdr.Gid != 0 {
+			t.Fatalf("expected %s to be owned by root, got %d:%d", hdr.Name, hdr.Uid, hdr.Gid)
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			entries[hdr.Name] = "dir"
+			continue
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[hdr.Name] = string(content)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := createContextDir(t, "Dockerfile", "main.go", "README.md", "docs/guide.md", "vendor/lib/lib.go", "vendor/keep/keep.go")
+	defer os.RemoveAll(dir)
+	dockerignore := "*.md\n!README.md\nvendor\n!vendor/keep\nDockerfile\n.dockerignore\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, ".dockerignore"), []byte(dockerignore), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	buildContext, err := Create(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer buildContext.Close()
+	if buildContext.Dockerfile != "Dockerfile" {
+		t.Fatalf("expected the Dockerfile in the context, got %s", buildContext.Dockerfile)
+	}
+	// The excluded vendor directory isn't archived, but the files included again are.
+	// The patterns without a directory only match the files at the root of the context.
+	expected := map[string]string{
+		".dockerignore":       dockerignore,
+		"Dockerfile":          "Dockerfile",
+		"README.md":           "README.md",
+		"docs/":               "dir",
+		"docs/guide.md":       "docs/guide.md",
+		"main.go":             "main.go",
+		"vendor/keep/":        "dir",
+		"vendor/keep/keep.go": "vendor/keep/keep.go",
+	}
+	if entries := readContext(t, buildContext); !reflect.DeepEqual(entries, expected) {
+		t.Fatalf("expected %v, got %v", expected, entries)
+	}
+	expectedSize := int64(len(dockerignore) + len("DockerfileREADME.mddocs/guide.mdmain.govendor/keep/keep.go"))
+	if buildContext.Files != 6 || buildContext.Size != expectedSize {
+		t.Fatalf("expected 6 files of %d bytes, got %d files of %d bytes", expectedSize, buildContext.Files, buildContext.Size)
+	}
+}
+
+func TestCreateDockerfileOutsideContext(t *testing.T) {
+	dir := createContextDir(t, "context/file", "build/Dockerfile.dev")
+	defer os.RemoveAll(dir)
+
+	buildContext, err := Create(filepath.Join(dir, "context"), Options{
+		Dockerfile: filepath.Join(dir, "build", "Dockerfile.dev"),
+		Compress:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer buildContext.Close()
+	if !strings.HasPrefix(buildContext.Dockerfile, ".dockerfile.") {
+		t.Fatalf("expected the Dockerfile to be renamed, got %s", buildContext.Dockerfile)
+	}
+
+	gr, err := gzip.NewReader(buildContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"file":                  "context/file",
+		buildContext.Dockerfile: "build/Dockerfile.dev",
+		".dockerignore":         buildContext.Dockerfile + "\n",
+	}
+	if entries := readContext(t, gr); !reflect.DeepEqual(entries, expected) {
+		t.Fatalf("expected %v, got %v", expected, entries)
+	}
+	if buildContext.Files != 3 {
+		t.Fatalf("expected 3 files, got %d", buildContext.Files)
+	}
+}
+
+func TestCreateDockerfileInSubdirectory(t *testing.T) {
+	dir := createContextDir(t, "file", "build/Dockerfile", "build/other")
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, ".dockerignore"), []byte("build\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	buildContext, err := Create(dir, Options{Dockerfile: filepath.Join(dir, "build", "Dockerfile")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer buildContext.Close()
+	if buildContext.Dockerfile != "build/Dockerfile" {
+		t.Fatalf("expected the Dockerfile in the build directory, got %s", buildContext.Dockerfile)
+	}
+	expected := map[string]string{
+		".dockerignore":    "build\n",
+		"build/Dockerfile": "build/Dockerfile",
+		"file":             "file",
+	}
+	if entries := readContext(t, buildContext); !reflect.DeepEqual(entries, expected) {
+		t.Fatalf("expected %v, got %v", expected, entries)
+	}
+}
+
+func TestCreateErrors(t *testing.T) {
+	dir := createContextDir(t, "file")
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		contextDir    string
+		options       Options
+		expectedError string
+	}{
+		{filepath.Join(dir, "missing"), Options{}, "unable to evaluate symlinks in context path"},
+		{filepath.Join(dir, "file"), Options{}, "context must be a directory"},
+		{dir, Options{}, "cannot locate specified Dockerfile"},
+		{dir, Options{Dockerfile: dir}, "is a directory"},
+	}
+	for _, c := range cases {
+		_, err := Create(c.contextDir, c.options)
+		if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+			t.Fatalf("expected an error containing %q, got %v", c.expectedError, err)
+		}
+	}
+}
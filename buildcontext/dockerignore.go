@@ -0,0 +1,172 @@
+package buildcontext
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// utf8BOM is the byte order mark some editors write
+// at the beginning of the .dockerignore file.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// ReadDockerignore reads the patterns of a .dockerignore file. Empty lines
+// and comments starting with "#" are skipped, and the patterns are cleaned
+// and use slashes as separators. Exception patterns start with "!".
+func ReadDockerignore(r io.Reader) ([]string, error) {
+	var excludes []string
+	scanner := bufio.NewScanner(r)
+	for first := true; scanner.Scan(); first = false {
+		line := scanner.Bytes()
+		if first {
+			line = bytes.TrimPrefix(line, utf8BOM)
+		}
+		pattern := strings.TrimSpace(string(line))
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+
+		exception := strings.HasPrefix(pattern, "!")
+		if exception {
+			pattern = strings.TrimSpace(pattern[1:])
+		}
+		if pattern != "" {
+			pattern = filepath.ToSlash(filepath.Clean(pattern))
+			if len(pattern) > 1 && pattern[0] == '/' {
+				pattern = pattern[1:]
+			}
+		}
+		if exception {
+			pattern = "!" + pattern
+		}
+		excludes = append(excludes, pattern)
+	}
+	return excludes, scanner.Err()
+}
+
+// patternMatcher matches the paths of the files of a build context
+// against the patterns of a .dockerignore file.
+type patternMatcher struct {
+	patterns   []*pattern
+	exceptions bool
+}
+
+type pattern struct {
+	text      string
+	dirs      int
+	regexp    *regexp.Regexp
+	exception bool
+}
+
+func newPatternMatcher(patterns []string) (*patternMatcher, error) {
+	pm := &patternMatcher{}
+	for _, text := range patterns {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		p := &pattern{}
+		if text[0] == '!' {
+			if len(text) == 1 {
+				return nil, errors.New("illegal exclusion pattern: \"!\"")
+			}
+			p.exception = true
+			pm.exceptions = true
+			text = text[1:]
+		}
+		p.text = path.Clean(filepath.ToSlash(text))
+		if _, err := path.Match(p.text, "."); err != nil {
+			return nil, err
+		}
+		p.dirs = len(strings.Split(p.text, "/"))
+		p.regexp = compilePattern(p.text)
+		pm.patterns = append(pm.patterns, p)
+	}
+	return pm, nil
+}
+
+// compilePattern converts a pattern to a regular expression: "*" matches
+// anything but a separator, "?" a single character other than a separator,
+// and "**" any number of directories.
+func compilePattern(text string) *regexp.Regexp {
+	var expr bytes.Buffer
+	expr.WriteString("^")
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '*' && i+1 < len(text) && text[i+1] == '*':
+			i++
+			if i+1 < len(text) && text[i+1] == '/' {
+				i++
+			}
+			if i+1 == len(text) {
+				expr.WriteString(".*")
+			} else {
+				expr.WriteString("(.*/)?")
+			}
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '\\' && i+1 < len(text):
+			i++
+			expr.WriteString(regexp.QuoteMeta(text[i : i+1]))
+		case c == '[':
+			// Character classes are the same in patterns and regular expressions,
+			// but a class never matches a separator.
+			end := strings.IndexByte(text[i:], ']')
+			if end < 0 {
+				expr.WriteString(regexp.QuoteMeta(text[i:]))
+				i = len(text)
+				break
+			}
+			class := text[i+1 : i+end]
+			if strings.HasPrefix(class, "^") {
+				class = "^/" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String())
+}
+
+// matches returns whether the file, relative to the root of the context, is
+// excluded. A file is excluded if the last pattern that matches it or one of
+// its parent directories isn't an exception.
+func (pm *patternMatcher) matches(file string) bool {
+	file = filepath.ToSlash(file)
+	parentPath := path.Dir(file)
+	parentPathDirs := strings.Split(parentPath, "/")
+
+	matched := false
+	for _, p := range pm.patterns {
+		match := p.regexp.MatchString(file)
+		if !match && parentPath != "." && p.dirs <= len(parentPathDirs) {
+			match = p.regexp.MatchString(strings.Join(parentPathDirs[:p.dirs], "/"))
+		}
+		if match {
+			matched = !p.exception
+		}
+	}
+	return matched
+}
+
+// mayIncludeChildren returns whether an exception pattern
+// may match a file in the excluded directory.
+func (pm *patternMatcher) mayIncludeChildren(dir string) bool {
+	dir = filepath.ToSlash(dir) + "/"
+	for _, p := range pm.patterns {
+		if p.exception && strings.HasPrefix(p.text+"/", dir) {
+			return true
+		}
+	}
+	return false
+}
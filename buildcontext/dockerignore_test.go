@@ -0,0 +1,70 @@
+package buildcontext
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadDockerignore(t *testing.T) {
+	content := "\xEF\xBB\xBFtest1\n  /test2/  \n# comment\n\n!test3\n! /test4/../test5\n"
+	excludes, err := ReadDockerignore(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"test1", "test2", "!test3", "!test5"}
+	if !reflect.DeepEqual(excludes, expected) {
+		t.Fatalf("expected %v, got %v", expected, excludes)
+	}
+}
+
+func TestPatternMatcher(t *testing.T) {
+	cases := []struct {
+		patterns []string
+		file     string
+		excluded bool
+	}{
+		{[]string{"*"}, "file", true},
+		{[]string{"*"}, "dir/file", true},
+		{[]string{"*.go"}, "file.go", true},
+		{[]string{"*.go"}, "dir/file.go", false},
+		{[]string{"dir"}, "dir/sub/file", true},
+		{[]string{"dir/*"}, "dir/file", true},
+		{[]string{"dir/*"}, "dirfile", false},
+		{[]string{"**/*.go"}, "file.go", true},
+		{[]string{"**/*.go"}, "dir/sub/file.go", true},
+		{[]string{"dir/**"}, "dir/sub/file", true},
+		{[]string{"dir/**/file"}, "dir/file", true},
+		{[]string{"dir/**/file"}, "dir/a/b/file", true},
+		{[]string{"file?"}, "file1", true},
+		{[]string{"file?"}, "file/", false},
+		{[]string{"file[0-9]"}, "file1", true},
+		{[]string{"file[^0-9]"}, "file1", false},
+		{[]string{"file.txt"}, "fileAtxt", false},
+		{[]string{`file\*`}, "file*", true},
+		{[]string{`file\*`}, "file1", false},
+		{[]string{"a+b"}, "a+b", true},
+		{[]string{"*.md", "!README.md"}, "README.md", false},
+		{[]string{"*.md", "!README.md"}, "CHANGELOG.md", true},
+		{[]string{"*.md", "!README.md", "README*"}, "README.md", true},
+		{[]string{"dir", "!dir/keep"}, "dir/keep", false},
+		{[]string{"dir", "!dir/keep"}, "dir/other", true},
+	}
+	for _, c := range cases {
+		pm, err := newPatternMatcher(c.patterns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if excluded := pm.matches(c.file); excluded != c.excluded {
+			t.Fatalf("expected %s to be excluded by %v: %v, got %v", c.file, c.patterns, c.excluded, excluded)
+		}
+	}
+}
+
+func TestPatternMatcherErrors(t *testing.T) {
+	for _, patterns := range [][]string{{"!"}, {"["}} {
+		if _, err := newPatternMatcher(patterns); err == nil {
+			t.Fatalf("expected an error for the patterns %v", patterns)
+		}
+	}
+}
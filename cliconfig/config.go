@@ -0,0 +1,289 @@
+// Package cliconfig loads and saves the configuration file of the docker
+// command line client, usually ~/.docker/config.json.
+//
+// The file holds the credentials of the registries the user logged in,
+// the credential helpers to get them from, the http headers to send
+// to the daemon and the defaults of some commands.
+package cliconfig
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/reference"
+)
+
+const (
+	// ConfigFileName is the name of the configuration file.
+	ConfigFileName = "config.json"
+	// IndexServer is the key of Docker Hub in the credentials.
+	// It's the address of its legacy index, kept for compatibility.
+	IndexServer = "https://index.docker.io/v1/"
+
+	configFileDir = ".docker"
+	dockerHub     = "docker.io"
+)
+
+// ConfigFile holds the configuration of the docker command line client.
+type ConfigFile struct {
+	AuthConfigs       map[string]types.AuthConfig `json:"auths"`
+	HTTPHeaders       map[string]string           `json:"HttpHeaders,omitempty"`
+	PsFormat          string                      `json:"psFormat,omitempty"`
+	ImagesFormat      string                      `json:"imagesFormat,omitempty"`
+	DetachKeys        string                      `json:"detachKeys,omitempty"`
+	CredentialsStore  string                      `json:"credsStore,omitempty"`
+	CredentialHelpers map[string]string           `json:"credHelpers,omitempty"`
+	Filename          string                      `json:"-"`
+
+	// other holds the fields of the file this package doesn't know,
+	// so they are saved back untouched.
+	other map[string]json.RawMessage
+}
+
+// Dir returns the directory of the configuration file, the value of
+// DOCKER_CONFIG or ~/.docker when it's not set.
+func Dir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	home := os.Getenv("HOME")
+	if home == "" {
+		home = os.Getenv("USERPROFILE")
+	}
+	return filepath.Join(home, configFileDir)
+}
+
+// New returns an empty configuration that saves to the given file.
+func New(filename string) *ConfigFile {
+	return &ConfigFile{
+		AuthConfigs: make(map[string]types.AuthConfig),
+		Filename:    filename,
+	}
+}
+
+// Load reads the configuration file from the given directory,
+// or from Dir if it's empty. It returns an empty configuration
+// if the file doesn't exist.
+func Load(configDir string) (*ConfigFile, error) {
+	if configDir == "" {
+		configDir = Dir()
+	}
+	configFile := New(filepath.Join(configDir, ConfigFileName))
+
+	file, err := os.Open(configFile.Filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return configFile, nil
+		}
+		return configFile, err
+	}
+	defer file.Close()
+
+	if err := configFile.LoadFromReader(file); err != nil {
+		return configFile, fmt.Errorf("Error loading config file %s: %v", configFile.Filename, err)
+	}
+	return configFile, nil
+}
+
+// LoadFromReader decodes the configuration from the reader, decoding
+// the credentials of every registry from their base64 auth field.
+func (configFile *ConfigFile) LoadFromReader(r io.Reader) error {
+	var other map[string]json.RawMessage
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, &other); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, configFile); err != nil {
+		return err
+	}
+	for _, key := range []string{"auths", "HttpHeaders", "psFormat", "imagesFormat", "detachKeys", "credsStore", "credHelpers"} {
+		delete(other, key)
+	}
+	configFile.other = other
+
+	if configFile.AuthConfigs == nil {
+		configFile.AuthConfigs = make(map[string]types.AuthConfig)
+	}
+	for addr, authConfig := range configFile.AuthConfigs {
+		if authConfig.Auth != "" {
+			authConfig.Username, authConfig.Password, err = decodeAuth(authConfig.Auth)
+			if err != nil {
+				return fmt.Errorf("invalid auth configuration for %s: %v", addr, err)
+			}
+			authConfig.Auth = ""
+		}
+		authConfig.ServerAddress = addr
+		configFile.AuthConfigs[addr] = authConfig
+	}
+	return nil
+}
+
+// Save writes the configuration to its file, encoding the credentials
+// of every registry in their base64 auth field.
+// The file is replaced atomically and only the user can read it.
+func (configFile *ConfigFile) Save() error {
+	if configFile.Filename == "" {
+		return fmt.Errorf("Can't save config with empty filename")
+	}
+	if err := os.MkdirAll(filepath.Dir(configFile.Filename), 0700); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(configFile.Filename), filepath.Base(configFile.Filename))
+	if err != nil {
+		return err
+	}
+	err = configFile.SaveToWriter(tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0600)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), configFile.Filename)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// SaveToWriter encodes the configuration to the writer.
+func (configFile *ConfigFile) SaveToWriter(w io.Writer) error {
+	auths := make(map[string]types.AuthConfig, len(configFile.AuthConfigs))
+	for addr, authConfig := range configFile.AuthConfigs {
+		if authConfig.Username != "" || authConfig.Password != "" {
+			authConfig.Auth = encodeAuth(authConfig.Username, authConfig.Password)
+		}
+		authConfig.Username = ""
+		authConfig.Password = ""
+		authConfig.ServerAddress = ""
+		auths[addr] = authConfig
+	}
+
+	saved := *configFile
+	saved.AuthConfigs = auths
+	content, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	if len(configFile.other) > 0 {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(content, &fields); err != nil {
+			return err
+		}
+		for key, value := range configFile.other {
+			fields[key] = value
+		}
+		if content, err = json.Marshal(fields); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, content, "", "\t"); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// AuthConfig returns the credentials stored for the given registry, looking
+// them up by the host name of the registry when there's no exact match.
+// It returns empty credentials with the server address of the registry
+// if there are none.
+func (configFile *ConfigFile) AuthConfig(registry string) types.AuthConfig {
+	if registry == "" || registry == dockerHub || registry == "index."+dockerHub {
+		registry = IndexServer
+	}
+	if authConfig, ok := configFile.AuthConfigs[registry]; ok {
+		return authConfig
+	}
+
+	hostname := RegistryHostname(registry)
+	for addr, authConfig := range configFile.AuthConfigs {
+		if RegistryHostname(addr) == hostname {
+			return authConfig
+		}
+	}
+	return types.AuthConfig{ServerAddress: registry}
+}
+
+// AuthConfigForImage returns the credentials stored for the registry
+// of the given image reference, see AuthConfig.
+func (configFile *ConfigFile) AuthConfigForImage(image string) (types.AuthConfig, error) {
+	registry, err := RegistryForImage(image)
+	if err != nil {
+		return types.AuthConfig{}, err
+	}
+	return configFile.AuthConfig(registry), nil
+}
+
+// RegistryForImage returns the key of the registry of the given image
+// reference in the credentials. It's IndexServer for Docker Hub images.
+func RegistryForImage(image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", err
+	}
+	if named.Domain() == reference.DefaultDomain {
+		return IndexServer, nil
+	}
+	return named.Domain(), nil
+}
+
+// RegistryHostname returns the host name of the registry with the given address,
+// like https://registry.example.com/v1/. All the addresses of Docker Hub
+// return the host name of its index.
+func RegistryHostname(addr string) string {
+	hostname := convertToHostname(addr)
+	switch hostname {
+	case dockerHub, "index." + dockerHub, "registry-1." + dockerHub:
+		return "index." + dockerHub
+	}
+	return hostname
+}
+
+// convertToHostname strips the scheme and the path from the address of
+// a registry, like https://registry.example.com/v1/.
+func convertToHostname(addr string) string {
+	if strings.Contains(addr, "://") {
+		if u, err := url.Parse(addr); err == nil && u.Host != "" {
+			return u.Host
+		}
+		addr = addr[strings.Index(addr, "://")+3:]
+	}
+	return strings.SplitN(addr, "/", 2)[0]
+}
+
+// encodeAuth encodes the credentials in the base64 auth field.
+func encodeAuth(username, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+}
+
+// decodeAuth decodes the credentials from the base64 auth field.
+func decodeAuth(auth string) (string, string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(auth)
+	if err != nil {
+		return "", "", err
+	}
+	parts := strings.SplitN(string(decoded), ":", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("Invalid auth configuration file")
+	}
+	return parts[0], strings.Trim(parts[1], "\x00"), nil
+}
@@ -0,0 +1,178 @@
+package cliconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/engine-api/types"
+)
+
+const testConfig = `{
+	"auths": {
+		"https://index.docker.io/v1/": {
+			"auth": "aHViOnNlY3JldA==",
+			"email": "hub@example.com"
+		},
+		"https://registry.example.com/v1/": {
+			"auth": "dXNlcjpwYXNzOndvcmQ="
+		},
+		"localhost:5000": {
+			"identitytoken": "token"
+		}
+	},
+	"HttpHeaders": {
+		"User-Agent": "tool"
+	},
+	"detachKeys": "ctrl-e,e",
+	"credsStore": "secretservice",
+	"credHelpers": {
+		"gcr.io": "gcloud"
+	},
+	"plugins": {
+		"foo": "bar"
+	}
+}`
+
+func TestLoadFromReader(t *testing.T) {
+	configFile := New("")
+	if err := configFile.LoadFromReader(strings.NewReader(testConfig)); err != nil {
+		t.Fatal(err)
+	}
+
+	hub := configFile.AuthConfigs[IndexServer]
+	if hub.Username != "hub" || hub.Password != "secret" || hub.Auth != "" || hub.Email != "hub@example.com" || hub.ServerAddress != IndexServer {
+		t.Fatalf("expected the decoded Docker Hub credentials, got %+v", hub)
+	}
+	registry := configFile.AuthConfigs["https://registry.example.com/v1/"]
+	if registry.Username != "user" || registry.Password != "pass:word" {
+		t.Fatalf("expected the decoded registry credentials, got %+v", registry)
+	}
+	if configFile.HTTPHeaders["User-Agent"] != "tool" || configFile.DetachKeys != "ctrl-e,e" {
+		t.Fatalf("expected the headers and the defaults, got %+v", configFile)
+	}
+	if configFile.CredentialsStore != "secretservice" || configFile.CredentialHelpers["gcr.io"] != "gcloud" {
+		t.Fatalf("expected the credential helpers, got %+v", configFile)
+	}
+}
+
+func TestLoadFromReaderInvalidAuth(t *testing.T) {
+	configFile := New("")
+	err := configFile.LoadFromReader(strings.NewReader(`{"auths":{"registry.example.com":{"auth":"bm9jb2xvbg=="}}}`))
+	if err == nil || !strings.Contains(err.Error(), "invalid auth configuration for registry.example.com") {
+		t.Fatalf("expected an invalid auth error, got %v", err)
+	}
+}
+
+func TestAuthConfigForImage(t *testing.T) {
+	configFile := New("")
+	if err := configFile.LoadFromReader(strings.NewReader(testConfig)); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		image            string
+		expectedUsername string
+		expectedToken    string
+		expectedAddress  string
+	}{
+		{image: "busybox", expectedUsername: "hub", expectedAddress: IndexServer},
+		{image: "library/busybox:latest", expectedUsername: "hub", expectedAddress: IndexServer},
+		{image: "docker.io/user/app", expectedUsername: "hub", expectedAddress: IndexServer},
+		{image: "index.docker.io/user/app", expectedUsername: "hub", expectedAddress: IndexServer},
+		{image: "localhost/app", expectedAddress: "localhost"},
+		{image: "registry.example.com/team/app:1.0", expectedUsername: "user", expectedAddress: "https://registry.example.com/v1/"},
+		{image: "localhost:5000/app", expectedToken: "token", expectedAddress: "localhost:5000"},
+		{image: "other.example.com/app", expectedAddress: "other.example.com"},
+	}
+	for _, c := range cases {
+		authConfig, err := configFile.AuthConfigForImage(c.image)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if authConfig.Username != c.expectedUsername || authConfig.IdentityToken != c.expectedToken || authConfig.ServerAddress != c.expectedAddress {
+			t.Fatalf("unexpected credentials for %s: %+v", c.image, authConfig)
+		}
+	}
+
+	if _, err := configFile.AuthConfigForImage("Invalid Image"); err == nil {
+		t.Fatal("expected an error for an invalid reference")
+	}
+}
+
+func TestAuthConfigDockerHubAliases(t *testing.T) {
+	configFile := New("")
+	if err := configFile.LoadFromReader(strings.NewReader(`{"auths":{"docker.io":{"auth":"aHViOnNlY3JldA=="}}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if authConfig := configFile.AuthConfig(IndexServer); authConfig.Username != "hub" {
+		t.Fatalf("expected the Docker Hub credentials, got %+v", authConfig)
+	}
+}
+
+func TestLoadAndSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cliconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	configFile, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configFile.AuthConfigs) != 0 || configFile.Filename != filepath.Join(dir, ConfigFileName) {
+		t.Fatalf("expected an empty configuration for a missing file, got %+v", configFile)
+	}
+
+	if err := ioutil.WriteFile(configFile.Filename, []byte(testConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	oldConfig := os.Getenv("DOCKER_CONFIG")
+	os.Setenv("DOCKER_CONFIG", dir)
+	defer os.Setenv("DOCKER_CONFIG", oldConfig)
+
+	configFile, err = Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	configFile.AuthConfigs["new.example.com"] = types.AuthConfig{Username: "new", Password: "password"}
+	if err := configFile.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(configFile.Filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("expected the file to be only readable by the user, got %v", info.Mode())
+	}
+
+	content, err := ioutil.ReadFile(configFile.Filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved map[string]json.RawMessage
+	if err := json.Unmarshal(content, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if string(saved["plugins"]) == "" || bytes.Contains(content, []byte("password")) || bytes.Contains(content, []byte("serveraddress")) {
+		t.Fatalf("expected unknown fields to be kept and credentials to be encoded, got %s", content)
+	}
+
+	reloaded, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authConfig := reloaded.AuthConfig("new.example.com"); authConfig.Username != "new" || authConfig.Password != "password" {
+		t.Fatalf("expected the saved credentials, got %+v", authConfig)
+	}
+	if authConfig := reloaded.AuthConfig("localhost:5000"); authConfig.IdentityToken != "token" {
+		t.Fatalf("expected the saved identity token, got %+v", authConfig)
+	}
+}
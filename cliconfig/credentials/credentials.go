@@ -0,0 +1,98 @@
+// Package credentials stores the credentials of the registries in the
+// configuration file of the docker command line client, or in the
+// credential helpers it configures.
+//
+// Credential helpers are programs named docker-credential-<name> that
+// keep the credentials in native stores, like the keychain of the
+// operating system. They are run with the action to perform as their
+// only argument, reading its input from stdin and writing its output
+// to stdout.
+package credentials
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/docker/engine-api/cliconfig"
+	"github.com/docker/engine-api/types"
+)
+
+// Store is the interface that any credentials store must implement.
+type Store interface {
+	// Erase removes the credentials of the registry from the store.
+	Erase(serverAddress string) error
+	// Get returns the credentials of the registry from the store.
+	// It returns empty credentials if there are none.
+	Get(serverAddress string) (types.AuthConfig, error)
+	// GetAll returns the credentials of all the registries in the store.
+	GetAll() (map[string]types.AuthConfig, error)
+	// Store saves the credentials of the registry in the store.
+	Store(authConfig types.AuthConfig) error
+}
+
+// DetectStore returns the store for the credentials of the given registry:
+// the credential helper configured for it in credHelpers, the default
+// credential helper configured in credsStore, or the configuration file.
+func DetectStore(configFile *cliconfig.ConfigFile, serverAddress string) Store {
+	if helper := helperForRegistry(configFile, serverAddress); helper != "" {
+		return NewNativeStore(helper)
+	}
+	return NewFileStore(configFile)
+}
+
+// helperForRegistry returns the name of the credential helper to use for
+// the given registry, or an empty string to use the configuration file.
+func helperForRegistry(configFile *cliconfig.ConfigFile, serverAddress string) string {
+	if serverAddress != "" && configFile.CredentialHelpers != nil {
+		if helper, ok := configFile.CredentialHelpers[serverAddress]; ok {
+			return helper
+		}
+		hostname := cliconfig.RegistryHostname(serverAddress)
+		for addr, helper := range configFile.CredentialHelpers {
+			if cliconfig.RegistryHostname(addr) == hostname {
+				return helper
+			}
+		}
+	}
+	return configFile.CredentialsStore
+}
+
+// EncodeAuth encodes the credentials in the format of the X-Registry-Auth
+// header, the value of the RegistryAuth fields in the request options.
+func EncodeAuth(authConfig types.AuthConfig) (string, error) {
+	buf, err := json.Marshal(authConfig)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(buf), nil
+}
+
+// RegistryAuth returns the encoded credentials of the given registry,
+// getting them from the store DetectStore returns.
+func RegistryAuth(configFile *cliconfig.ConfigFile, serverAddress string) (string, error) {
+	authConfig, err := DetectStore(configFile, serverAddress).Get(serverAddress)
+	if err != nil {
+		return "", err
+	}
+	return EncodeAuth(authConfig)
+}
+
+// RegistryAuthForImage returns the encoded credentials of the registry
+// of the given image reference, see RegistryAuth.
+func RegistryAuthForImage(configFile *cliconfig.ConfigFile, image string) (string, error) {
+	serverAddress, err := cliconfig.RegistryForImage(image)
+	if err != nil {
+		return "", err
+	}
+	return RegistryAuth(configFile, serverAddress)
+}
+
+// PrivilegeFunc returns a function that gets the encoded credentials of the
+// registry of the given image reference when they are requested. Use it in
+// the PrivilegeFunc fields of the request options to send the credentials
+// only when the registry rejects the request without them.
+func PrivilegeFunc(configFile *cliconfig.ConfigFile, image string) types.RequestPrivilegeFunc {
+	return func() (string, error) {
+		return RegistryAuthForImage(configFile, image)
+	}
+}
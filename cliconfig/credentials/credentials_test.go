@@ -0,0 +1,215 @@
+package credentials
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/docker/engine-api/cliconfig"
+	"github.com/docker/engine-api/types"
+)
+
+// fakeHelper implements the protocol of the credential helpers,
+// keeping the credentials in the directory in FAKE_HELPER_STORE.
+const fakeHelper = `#!/bin/sh
+store="$FAKE_HELPER_STORE"
+key() {
+	tr -c 'a-zA-Z0-9' '_'
+}
+case "$1" in
+get)
+	k=$(key)
+	if [ ! -f "$store/$k.json" ]; then
+		echo "credentials not found in native keychain"
+		exit 1
+	fi
+	cat "$store/$k.json"
+	;;
+store)
+	input=$(cat)
+	url=$(printf '%s' "$input" | sed -n 's/.*"ServerURL":"\([^"]*\)".*/\1/p')
+	k=$(printf '%s' "$url" | key)
+	printf '%s' "$input" > "$store/$k.json"
+	printf '%s' "$url" > "$store/$k.url"
+	;;
+erase)
+	k=$(key)
+	if [ ! -f "$store/$k.json" ]; then
+		echo "credentials not found in native keychain"
+		exit 1
+	fi
+	rm "$store/$k.json" "$store/$k.url"
+	;;
+list)
+	printf '{'
+	sep=''
+	for f in "$store"/*.url; do
+		[ -f "$f" ] || continue
+		printf '%s"%s":"user"' "$sep" "$(cat "$f")"
+		sep=','
+	done
+	printf '}'
+	;;
+*)
+	echo "unknown action $1"
+	exit 1
+	;;
+esac
+`
+
+// setupFakeHelper installs the fake helper with the given name in the PATH.
+// It returns a function to restore the environment.
+func setupFakeHelper(t *testing.T, name string) func() {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake credential helper is a shell script")
+	}
+	dir, err := ioutil.TempDir("", "credential-helper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := filepath.Join(dir, "store")
+	if err := os.Mkdir(store, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, remoteCredentialsPrefix+name), []byte(fakeHelper), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	oldPath, oldStore := os.Getenv("PATH"), os.Getenv("FAKE_HELPER_STORE")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+oldPath)
+	os.Setenv("FAKE_HELPER_STORE", store)
+	return func() {
+		os.Setenv("PATH", oldPath)
+		os.Setenv("FAKE_HELPER_STORE", oldStore)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestNativeStore(t *testing.T) {
+	defer setupFakeHelper(t, "fake")()
+	store := NewNativeStore("fake")
+
+	authConfig, err := store.Get("registry.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authConfig != (types.AuthConfig{ServerAddress: "registry.example.com"}) {
+		t.Fatalf("expected empty credentials, got %+v", authConfig)
+	}
+
+	if err := store.Store(types.AuthConfig{ServerAddress: "registry.example.com", Username: "user", Password: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Store(types.AuthConfig{ServerAddress: cliconfig.IndexServer, IdentityToken: "token"}); err != nil {
+		t.Fatal(err)
+	}
+
+	authConfig, err = store.Get("registry.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authConfig.Username != "user" || authConfig.Password != "secret" || authConfig.ServerAddress != "registry.example.com" {
+		t.Fatalf("expected the stored credentials, got %+v", authConfig)
+	}
+	authConfig, err = store.Get(cliconfig.IndexServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authConfig.IdentityToken != "token" || authConfig.Username != "" || authConfig.Password != "" {
+		t.Fatalf("expected the stored identity token, got %+v", authConfig)
+	}
+
+	all, err := store.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all["registry.example.com"].Password != "secret" || all[cliconfig.IndexServer].IdentityToken != "token" {
+		t.Fatalf("expected the credentials of both registries, got %+v", all)
+	}
+
+	if err := store.Erase("registry.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Erase("registry.example.com"); err != nil {
+		t.Fatalf("expected erasing missing credentials to succeed, got %v", err)
+	}
+	authConfig, err = store.Get("registry.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authConfig.Username != "" {
+		t.Fatalf("expected the credentials to be erased, got %+v", authConfig)
+	}
+}
+
+func TestNativeStoreErrors(t *testing.T) {
+	defer setupFakeHelper(t, "fake")()
+
+	_, err := NewNativeStore("missing").Get("registry.example.com")
+	if err == nil || !strings.Contains(err.Error(), "docker-credential-missing get") {
+		t.Fatalf("expected an error running the missing helper, got %v", err)
+	}
+
+	_, err = NewNativeStore("fake").(*nativeStore).run("unknown", nil)
+	if err == nil || err.Error() != "error running docker-credential-fake unknown: unknown action unknown" {
+		t.Fatalf("expected the output of the helper as error, got %v", err)
+	}
+}
+
+func TestDetectStore(t *testing.T) {
+	configFile := cliconfig.New("")
+	configFile.CredentialHelpers = map[string]string{"https://gcr.io": "gcloud"}
+
+	if _, ok := DetectStore(configFile, "registry.example.com").(*fileStore); !ok {
+		t.Fatal("expected the file store without helpers")
+	}
+	if store, ok := DetectStore(configFile, "gcr.io").(*nativeStore); !ok || store.program != "docker-credential-gcloud" {
+		t.Fatalf("expected the helper of the registry, got %+v", store)
+	}
+
+	configFile.CredentialsStore = "secretservice"
+	if store, ok := DetectStore(configFile, "registry.example.com").(*nativeStore); !ok || store.program != "docker-credential-secretservice" {
+		t.Fatalf("expected the default helper, got %+v", store)
+	}
+}
+
+func TestPrivilegeFunc(t *testing.T) {
+	defer setupFakeHelper(t, "fake")()
+	if err := NewNativeStore("fake").Store(types.AuthConfig{ServerAddress: "registry.example.com", Username: "user", Password: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+
+	configFile := cliconfig.New("")
+	configFile.AuthConfigs[cliconfig.IndexServer] = types.AuthConfig{Username: "hub", Password: "password"}
+	configFile.CredentialHelpers = map[string]string{"registry.example.com": "fake"}
+
+	cases := []struct {
+		image            string
+		expectedUsername string
+	}{
+		{image: "busybox", expectedUsername: "hub"},
+		{image: "registry.example.com/app:latest", expectedUsername: "user"},
+	}
+	for _, c := range cases {
+		encoded, err := PrivilegeFunc(configFile, c.image)()
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := base64.URLEncoding.DecodeString(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var authConfig types.AuthConfig
+		if err := json.Unmarshal(decoded, &authConfig); err != nil {
+			t.Fatal(err)
+		}
+		if authConfig.Username != c.expectedUsername {
+			t.Fatalf("expected the credentials of %s for %s, got %+v", c.expectedUsername, c.image, authConfig)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package credentials
+
+import (
+	"github.com/docker/engine-api/cliconfig"
+	"github.com/docker/engine-api/types"
+)
+
+// fileStore stores the credentials in the configuration file.
+type fileStore struct {
+	file *cliconfig.ConfigFile
+}
+
+// NewFileStore creates a new store that keeps the credentials
+// in the given configuration file.
+func NewFileStore(file *cliconfig.ConfigFile) Store {
+	return &fileStore{
+		file: file,
+	}
+}
+
+// Erase removes the credentials of the registry from the file.
+func (c *fileStore) Erase(serverAddress string) error {
+	delete(c.file.AuthConfigs, serverAddress)
+	return c.file.Save()
+}
+
+// Get returns the credentials of the registry from the file.
+func (c *fileStore) Get(serverAddress string) (types.AuthConfig, error) {
+	return c.file.AuthConfig(serverAddress), nil
+}
+
+// GetAll returns the credentials of all the registries in the file.
+func (c *fileStore) GetAll() (map[string]types.AuthConfig, error) {
+	return c.file.AuthConfigs, nil
+}
+
+// Store saves the credentials of the registry in the file.
+func (c *fileStore) Store(authConfig types.AuthConfig) error {
+	c.file.AuthConfigs[authConfig.ServerAddress] = authConfig
+	return c.file.Save()
+}
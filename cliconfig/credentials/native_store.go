@@ -0,0 +1,145 @@
+package credentials
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/docker/engine-api/types"
+)
+
+const (
+	remoteCredentialsPrefix = "docker-credential-"
+	// tokenUsername is the username helpers store identity tokens with.
+	tokenUsername = "<token>"
+	// errCredentialsNotFoundMessage is the output of helpers
+	// when they don't have the requested credentials.
+	errCredentialsNotFoundMessage = "credentials not found in native keychain"
+)
+
+// errCredentialsNotFound is returned when a helper doesn't have the requested credentials.
+type errCredentialsNotFound struct{}
+
+func (errCredentialsNotFound) Error() string {
+	return errCredentialsNotFoundMessage
+}
+
+// helperCredentials holds the credentials helpers read and write.
+type helperCredentials struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+// nativeStore stores the credentials with a credential helper.
+type nativeStore struct {
+	program string
+}
+
+// NewNativeStore creates a new store that keeps the credentials with the
+// credential helper with the given name, the docker-credential-<name>
+// program in the PATH.
+func NewNativeStore(helper string) Store {
+	return &nativeStore{
+		program: remoteCredentialsPrefix + helper,
+	}
+}
+
+// Erase removes the credentials of the registry from the helper.
+func (c *nativeStore) Erase(serverAddress string) error {
+	_, err := c.run("erase", strings.NewReader(serverAddress))
+	if _, ok := err.(errCredentialsNotFound); ok {
+		return nil
+	}
+	return err
+}
+
+// Get returns the credentials of the registry from the helper.
+func (c *nativeStore) Get(serverAddress string) (types.AuthConfig, error) {
+	out, err := c.run("get", strings.NewReader(serverAddress))
+	if err != nil {
+		if _, ok := err.(errCredentialsNotFound); ok {
+			return types.AuthConfig{ServerAddress: serverAddress}, nil
+		}
+		return types.AuthConfig{}, err
+	}
+
+	var creds helperCredentials
+	if err := json.Unmarshal(out, &creds); err != nil {
+		return types.AuthConfig{}, fmt.Errorf("error reading credentials from %s: %v", c.program, err)
+	}
+
+	authConfig := types.AuthConfig{ServerAddress: serverAddress}
+	if creds.Username == tokenUsername {
+		authConfig.IdentityToken = creds.Secret
+	} else {
+		authConfig.Username = creds.Username
+		authConfig.Password = creds.Secret
+	}
+	return authConfig, nil
+}
+
+// GetAll returns the credentials of all the registries in the helper.
+func (c *nativeStore) GetAll() (map[string]types.AuthConfig, error) {
+	out, err := c.run("list", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs map[string]string
+	if err := json.Unmarshal(out, &addrs); err != nil {
+		return nil, fmt.Errorf("error reading credentials from %s: %v", c.program, err)
+	}
+
+	authConfigs := make(map[string]types.AuthConfig, len(addrs))
+	for addr := range addrs {
+		authConfig, err := c.Get(addr)
+		if err != nil {
+			return nil, err
+		}
+		authConfigs[addr] = authConfig
+	}
+	return authConfigs, nil
+}
+
+// Store saves the credentials of the registry in the helper.
+func (c *nativeStore) Store(authConfig types.AuthConfig) error {
+	creds := helperCredentials{
+		ServerURL: authConfig.ServerAddress,
+		Username:  authConfig.Username,
+		Secret:    authConfig.Password,
+	}
+	if authConfig.IdentityToken != "" {
+		creds.Username = tokenUsername
+		creds.Secret = authConfig.IdentityToken
+	}
+
+	buf, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	_, err = c.run("store", bytes.NewReader(buf))
+	return err
+}
+
+// run runs the helper to perform the action with the given input.
+// Helpers write their errors to stdout.
+func (c *nativeStore) run(action string, input io.Reader) ([]byte, error) {
+	cmd := exec.Command(c.program, action)
+	cmd.Stdin = input
+	out, err := cmd.Output()
+	if err != nil {
+		message := strings.TrimSpace(string(out))
+		if message == errCredentialsNotFoundMessage {
+			return nil, errCredentialsNotFound{}
+		}
+		if message == "" {
+			return nil, fmt.Errorf("error running %s %s: %v", c.program, action, err)
+		}
+		return nil, fmt.Errorf("error running %s %s: %s", c.program, action, message)
+	}
+	return out, nil
+}
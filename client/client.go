@@ -2,14 +2,14 @@ package client
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
-	"os"
-	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/docker/engine-api/client/transport"
-	"github.com/docker/go-connections/tlsconfig"
+	"github.com/docker/go-connections/sockets"
 )
 
 // DefaultVersion is the version of the current stable API
@@ -32,6 +32,26 @@ type Client struct {
 	version string
 	// custom http headers configured by users.
 	customHTTPHeaders map[string]string
+	// middlewares to send the requests through.
+	middlewares []Middleware
+	// retryPolicy to retry failed requests with, nil disables retries.
+	retryPolicy *RetryPolicy
+	// negotiateVersion enables the negotiation of the API version with the server.
+	negotiateVersion bool
+	// negotiated is set once the API version has been negotiated.
+	negotiated bool
+	// negotiateMu serializes the negotiation of the API version.
+	negotiateMu sync.Mutex
+	// versionMu guards the version, which the negotiation updates while
+	// the requests are sent. The version is only updated with negotiateMu
+	// held too, the negotiation reads it without versionMu.
+	versionMu sync.RWMutex
+	// client is the http client the transport is created with.
+	client *http.Client
+	// scheme overrides the protocol scheme of the transport.
+	scheme string
+	// dialer to open the connections to the server with, if any.
+	dialer *net.Dialer
 }
 
 // NewEnvClient initializes a new API client based on environment variables.
@@ -40,65 +60,77 @@ type Client struct {
 // Use DOCKER_CERT_PATH to load the tls certificates from.
 // Use DOCKER_TLS_VERIFY to enable or disable TLS verification, off by default.
 func NewEnvClient() (*Client, error) {
-	var client *http.Client
-	if dockerCertPath := os.Getenv("DOCKER_CERT_PATH"); dockerCertPath != "" {
-		options := tlsconfig.Options{
-			CAFile:             filepath.Join(dockerCertPath, "ca.pem"),
-			CertFile:           filepath.Join(dockerCertPath, "cert.pem"),
-			KeyFile:            filepath.Join(dockerCertPath, "key.pem"),
-			InsecureSkipVerify: os.Getenv("DOCKER_TLS_VERIFY") == "",
-		}
-		tlsc, err := tlsconfig.Client(options)
-		if err != nil {
-			return nil, err
-		}
-
-		client = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: tlsc,
-			},
-		}
-	}
-
-	host := os.Getenv("DOCKER_HOST")
-	if host == "" {
-		host = DefaultDockerHost
-	}
-
-	version := os.Getenv("DOCKER_API_VERSION")
-	if version == "" {
-		version = DefaultVersion
-	}
-
-	return NewClient(host, version, client, nil)
+	return NewClientWithOpts(FromEnv)
 }
 
 // NewClient initializes a new API client for the given host and API version.
 // It uses the given http client as transport.
-// It also initializes the custom http headers to add to each request.
+// It also initializes the custom http headers to add to each request,
+// and the middlewares to send each request through.
 //
 // It won't send any version information if the version number is empty. It is
 // highly recommended that you set a version or your client may break if the
 // server is upgraded.
-func NewClient(host string, version string, client *http.Client, httpHeaders map[string]string) (*Client, error) {
-	proto, addr, basePath, err := ParseHost(host)
+func NewClient(host string, version string, client *http.Client, httpHeaders map[string]string, middlewares ...Middleware) (*Client, error) {
+	opts := []Opt{WithHost(host), WithVersion(version)}
+	if client != nil {
+		opts = append(opts, WithHTTPClient(client))
+	}
+	opts = append(opts, WithHTTPHeaders(httpHeaders), WithMiddlewares(middlewares...))
+	return NewClientWithOpts(opts...)
+}
+
+// NewClientWithOpts initializes a new API client with the default host
+// and API version, and the given options applied in order.
+func NewClientWithOpts(opts ...Opt) (*Client, error) {
+	client, err := defaultHTTPClient(DefaultDockerHost)
+	if err != nil {
+		return nil, err
+	}
+	proto, addr, basePath, err := ParseHost(DefaultDockerHost)
 	if err != nil {
 		return nil, err
 	}
+	c := &Client{
+		host:     DefaultDockerHost,
+		proto:    proto,
+		addr:     addr,
+		basePath: basePath,
+		version:  DefaultVersion,
+		client:   client,
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
 
-	transport, err := transport.NewTransportWithHTTP(proto, addr, client)
+	if c.dialer != nil {
+		if err := c.useDialer(); err != nil {
+			return nil, err
+		}
+	}
+
+	c.transport, err = transport.NewTransportWithHTTP(c.proto, c.addr, c.client)
 	if err != nil {
 		return nil, err
 	}
+	return c, nil
+}
 
-	return &Client{
-		host:              host,
-		proto:             proto,
-		addr:              addr,
-		basePath:          basePath,
-		transport:         transport,
-		version:           version,
-		customHTTPHeaders: httpHeaders,
+// defaultHTTPClient returns a new http client configured to connect to the given host.
+func defaultHTTPClient(host string) (*http.Client, error) {
+	proto, addr, _, err := ParseHost(host)
+	if err != nil {
+		return nil, err
+	}
+	transport := new(http.Transport)
+	if err := sockets.ConfigureTransport(transport, proto, addr); err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Transport: transport,
 	}, nil
 }
 
@@ -106,8 +138,8 @@ func NewClient(host string, version string, client *http.Client, httpHeaders map
 // It appends the query parameters to the path if they are not empty.
 func (cli *Client) getAPIPath(p string, query url.Values) string {
 	var apiPath string
-	if cli.version != "" {
-		v := strings.TrimPrefix(cli.version, "v")
+	if version := cli.ClientVersion(); version != "" {
+		v := strings.TrimPrefix(version, "v")
 		apiPath = fmt.Sprintf("%s/v%s%s", cli.basePath, v, p)
 	} else {
 		apiPath = fmt.Sprintf("%s%s", cli.basePath, p)
@@ -122,16 +154,49 @@ func (cli *Client) getAPIPath(p string, query url.Values) string {
 	return u.String()
 }
 
+// urlHost returns the host to send the requests to.
+func (cli *Client) urlHost() string {
+	if cli.proto == "ssh" {
+		// The transport tunnels the connections to the remote socket,
+		// and the address of ssh hosts is not a valid host name.
+		return "docker"
+	}
+	return cli.addr
+}
+
+// getScheme returns the protocol scheme to send the requests with.
+func (cli *Client) getScheme() string {
+	if cli.scheme != "" {
+		return cli.scheme
+	}
+	return cli.transport.Scheme()
+}
+
 // ClientVersion returns the version string associated with this
 // instance of the Client. Note that this value can be changed
-// via the DOCKER_API_VERSION env var.
+// via the DOCKER_API_VERSION env var, or by negotiating it with the daemon.
 func (cli *Client) ClientVersion() string {
+	cli.versionMu.RLock()
+	defer cli.versionMu.RUnlock()
+
 	return cli.version
 }
 
 // UpdateClientVersion updates the version string associated with this
-// instance of the Client.
+// instance of the Client. It pins the version, disabling its negotiation.
 func (cli *Client) UpdateClientVersion(v string) {
+	cli.negotiateMu.Lock()
+	defer cli.negotiateMu.Unlock()
+
+	cli.setVersion(v)
+	cli.negotiateVersion = false
+}
+
+// setVersion updates the version of the API the requests are sent with.
+func (cli *Client) setVersion(v string) {
+	cli.versionMu.Lock()
+	defer cli.versionMu.Unlock()
+
 	cli.version = v
 }
 
@@ -152,5 +217,11 @@ func ParseHost(host string) (string, string, string, error) {
 		addr = parsed.Host
 		basePath = parsed.Path
 	}
+	if proto == "ssh" {
+		// The path of ssh hosts is the path of the remote docker socket.
+		if _, _, _, err := transport.ParseSSHAddr(addr); err != nil {
+			return "", "", "", err
+		}
+	}
 	return proto, addr, basePath, nil
 }
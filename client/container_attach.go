@@ -11,7 +11,15 @@ import (
 // It returns a types.HijackedConnection with the hijacked connection
 // and the a reader to get output. It's up to the called to close
 // the hijacked connection by calling types.HijackedResponse.Close.
+//
+// The output is multiplexed when there is no TTY attached,
+// use stdcopy.StdCopy to split it into stdout and stderr.
 func (cli *Client) ContainerAttach(ctx context.Context, container string, options types.ContainerAttachOptions) (types.HijackedResponse, error) {
+	headers := map[string][]string{"Content-Type": {"text/plain"}}
+	return cli.postHijacked(ctx, "/containers/"+container+"/attach", containerAttachQuery(options), nil, headers)
+}
+
+func containerAttachQuery(options types.ContainerAttachOptions) url.Values {
 	query := url.Values{}
 	if options.Stream {
 		query.Set("stream", "1")
@@ -28,7 +36,8 @@ func (cli *Client) ContainerAttach(ctx context.Context, container string, option
 	if options.DetachKeys != "" {
 		query.Set("detachKeys", options.DetachKeys)
 	}
-
-	headers := map[string][]string{"Content-Type": {"text/plain"}}
-	return cli.postHijacked(ctx, "/containers/"+container+"/attach", query, nil, headers)
+	if options.Logs {
+		query.Set("logs", "1")
+	}
+	return query
 }
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+	"golang.org/x/net/websocket"
+)
+
+// webSocketGUID is appended to the key of the WebSocket handshake
+// to compute the key accepting it.
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// ContainerAttachWebSocket attaches a connection to a container in the server
+// over a WebSocket, for the networks where the proxies don't pass the upgrade
+// of the connection ContainerAttach requests.
+// It returns a types.HijackedConnection with the WebSocket connection
+// and a reader to get output. It's up to the caller to close the
+// connection by calling types.HijackedResponse.Close.
+//
+// The output is never multiplexed, the streams the options select are merged.
+func (cli *Client) ContainerAttachWebSocket(ctx context.Context, container string, options types.ContainerAttachOptions) (types.HijackedResponse, error) {
+	if err := cli.negotiateAPIVersionOnce(ctx); err != nil {
+		return types.HijackedResponse{}, err
+	}
+
+	req, err := cli.newRequest("GET", "/containers/"+container+"/attach/ws", containerAttachQuery(options), nil, nil)
+	if err != nil {
+		return types.HijackedResponse{}, err
+	}
+	req.Host = cli.urlHost()
+	req.URL.Host = cli.urlHost()
+	req.URL.Scheme = cli.getScheme()
+
+	host := cli.addr
+	if cli.proto != "tcp" {
+		// Like the other requests to local sockets, send a valid host name.
+		host = "docker"
+	}
+	origin := &url.URL{Scheme: req.URL.Scheme, Host: host}
+	location := &url.URL{Scheme: "ws", Host: host, Path: req.URL.Path, RawQuery: req.URL.RawQuery}
+	if req.URL.Scheme == "https" {
+		location.Scheme = "wss"
+	}
+
+	key, err := newWebSocketKey()
+	if err != nil {
+		return types.HijackedResponse{}, err
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Origin", origin.String())
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+
+	conn, br, resp, err := cli.sendUpgrade(ctx, req)
+	if err != nil {
+		return types.HijackedResponse{}, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		err := cli.responseError(req, resp)
+		conn.Close()
+		return types.HijackedResponse{}, err
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != webSocketAccept(key) {
+		conn.Close()
+		return types.HijackedResponse{}, websocket.ErrChallengeResponse
+	}
+
+	config := &websocket.Config{
+		Location: location,
+		Origin:   origin,
+		Version:  websocket.ProtocolVersionHybi13,
+	}
+	ws, err := websocket.NewClient(config, &upgradedWebSocketConn{Conn: conn, br: br})
+	if err != nil {
+		conn.Close()
+		return types.HijackedResponse{}, err
+	}
+	// The input is sent in binary frames so it's not checked to be valid UTF-8.
+	ws.PayloadType = websocket.BinaryFrame
+
+	return types.HijackedResponse{Conn: ws, Reader: bufio.NewReader(ws)}, nil
+}
+
+// newWebSocketKey returns a random key for the WebSocket handshake.
+func newWebSocketKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// webSocketAccept returns the key accepting the key of a WebSocket handshake.
+func webSocketAccept(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// upgradedWebSocketConn is a connection the docker host already upgraded
+// to the WebSocket protocol. websocket.NewClient always sends a handshake:
+// it's discarded, and answered with a response accepting it, before the
+// frames the docker host sends.
+type upgradedWebSocketConn struct {
+	net.Conn
+	br        *bufio.Reader
+	handshake bytes.Buffer
+	response  *bytes.Reader
+}
+
+func (c *upgradedWebSocketConn) Read(p []byte) (int, error) {
+	if c.response == nil {
+		req, err := http.ReadRequest(bufio.NewReader(&c.handshake))
+		if err != nil {
+			return 0, err
+		}
+		c.response = bytes.NewReader([]byte("HTTP/1.1 101 Switching Protocols\r\n" +
+			"Upgrade: websocket\r\n" +
+			"Connection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + webSocketAccept(req.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n"))
+	}
+	if c.response.Len() > 0 {
+		return c.response.Read(p)
+	}
+	return c.br.Read(p)
+}
+
+func (c *upgradedWebSocketConn) Write(p []byte) (int, error) {
+	if c.response == nil {
+		return c.handshake.Write(p)
+	}
+	return c.Conn.Write(p)
+}
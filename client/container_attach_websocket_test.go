@@ -0,0 +1,94 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"golang.org/x/net/context"
+	"golang.org/x/net/websocket"
+
+	"github.com/docker/engine-api/types"
+)
+
+func TestContainerAttachWebSocket(t *testing.T) {
+	errC := make(chan error, 1)
+	server, client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1.24/containers/container_id/attach/ws" {
+			errC <- fmt.Errorf("unexpected path %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		query := r.URL.Query()
+		for _, param := range []string{"stream", "stdin", "stdout", "logs"} {
+			if query.Get(param) != "1" {
+				errC <- fmt.Errorf("expected %s to be set, got %v", param, query)
+			}
+		}
+		if query.Get("stderr") != "" || query.Get("detachKeys") != "ctrl-e,e" {
+			errC <- fmt.Errorf("unexpected query %v", query)
+		}
+		websocket.Handler(func(ws *websocket.Conn) {
+			defer ws.Close()
+			fmt.Fprint(ws, "previous output\n")
+			// Echo the input back to the client.
+			input := make([]byte, 64)
+			n, err := ws.Read(input)
+			if err != nil {
+				errC <- err
+				return
+			}
+			fmt.Fprintf(ws, "received %s", input[:n])
+			errC <- nil
+		}).ServeHTTP(w, r)
+	})
+	defer server.Close()
+
+	resp, err := client.ContainerAttachWebSocket(context.Background(), "container_id", types.ContainerAttachOptions{
+		Stream:     true,
+		Stdin:      true,
+		Stdout:     true,
+		Logs:       true,
+		DetachKeys: "ctrl-e,e",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+
+	line, err := resp.Reader.ReadString('\n')
+	if err != nil || line != "previous output\n" {
+		t.Fatalf("expected the logs of the container, got %q and %v", line, err)
+	}
+	if _, err := io.WriteString(resp.Conn, "input"); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errC; err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadAll(resp.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "received input" {
+		t.Fatalf("expected the echoed input, got %q", string(content))
+	}
+}
+
+func TestContainerAttachWebSocketError(t *testing.T) {
+	server, client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "No such container: container_id", http.StatusNotFound)
+	})
+	defer server.Close()
+
+	_, err := client.ContainerAttachWebSocket(context.Background(), "container_id", types.ContainerAttachOptions{Stream: true})
+	if err == nil || !IsErrNotFound(err) || !IsErrContainerNotFound(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+	serverErr, ok := AsServerError(err)
+	if !ok || serverErr.Method != "GET" || serverErr.Path != "/v1.24/containers/container_id/attach/ws" || serverErr.Response.Message != "No such container: container_id" {
+		t.Fatalf("expected the error of the daemon, got %#v", err)
+	}
+}
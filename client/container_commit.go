@@ -5,7 +5,6 @@ import (
 	"errors"
 	"net/url"
 
-	distreference "github.com/docker/distribution/reference"
 	"github.com/docker/engine-api/types"
 	"github.com/docker/engine-api/types/reference"
 	"golang.org/x/net/context"
@@ -15,17 +14,18 @@ import (
 func (cli *Client) ContainerCommit(ctx context.Context, container string, options types.ContainerCommitOptions) (types.ContainerCommitResponse, error) {
 	var repository, tag string
 	if options.Reference != "" {
-		distributionRef, err := distreference.ParseNamed(options.Reference)
+		named, err := reference.ParseNormalizedNamed(options.Reference)
 		if err != nil {
 			return types.ContainerCommitResponse{}, err
 		}
 
-		if _, isCanonical := distributionRef.(distreference.Canonical); isCanonical {
+		if named.Digest() != "" {
 			return types.ContainerCommitResponse{}, errors.New("refusing to create a tag with a digest reference")
 		}
 
-		tag = reference.GetTagFromNamedRef(distributionRef)
-		repository = distributionRef.Name()
+		named = named.WithDefaultTag()
+		tag = named.Tag()
+		repository = named.FamiliarName()
 	}
 
 	query := url.Values{}
@@ -37,6 +37,9 @@ func (cli *Client) CopyToContainer(ctx context.Context, container, path string,
 	if !options.AllowOverwriteDirWithFile {
 		query.Set("noOverwriteDirNonDir", "true")
 	}
+	if options.CopyUIDGID {
+		query.Set("copyUIDGID", "true")
+	}
 
 	apiPath := fmt.Sprintf("/containers/%s/archive", container)
 
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/engine-api/archive"
+	"github.com/docker/engine-api/types"
+)
+
+// CopyPathToContainer copies the file or directory at the source path on the
+// host to the destination path in the container, with the semantics of `docker cp`.
+//
+// The source is copied into the destination if it's an existing directory,
+// or replaces it if both are files. Otherwise the source is copied as the
+// destination, in its parent directory that must exist. A source path ending
+// with "/." copies the content of the directory instead of the directory itself.
+func (cli *Client) CopyPathToContainer(ctx context.Context, container, srcPath, dstPath string, options types.CopyPathOptions) error {
+	// A destination that doesn't exist is created by the copy.
+	dstInfo := archive.CopyInfo{Path: dstPath}
+	dstStat, err := cli.ContainerStatPath(ctx, container, dstPath)
+	if err == nil && dstStat.Mode&os.ModeSymlink != 0 {
+		// The source is copied to the target of the link, not over the link.
+		dstInfo.Path = resolveLinkTarget(dstPath, dstStat.LinkTarget)
+		dstStat, err = cli.ContainerStatPath(ctx, container, dstInfo.Path)
+	}
+	if err != nil && !IsErrNotFound(err) {
+		return err
+	}
+	if err == nil {
+		dstInfo.Exists, dstInfo.IsDir = true, dstStat.Mode.IsDir()
+	}
+
+	srcInfo, err := archive.CopyInfoSourcePath(srcPath, options.FollowLink)
+	if err != nil {
+		return err
+	}
+	content, err := archive.TarResource(srcInfo)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	dstDir, preparedArchive, err := archive.PrepareArchiveCopy(content, srcInfo, dstInfo)
+	if err != nil {
+		return err
+	}
+	defer preparedArchive.Close()
+
+	return cli.CopyToContainer(ctx, container, dstDir, preparedArchive, types.CopyToContainerOptions{
+		CopyUIDGID: options.CopyUIDGID,
+	})
+}
+
+// CopyPathFromContainer copies the file or directory at the source path in
+// the container to the destination path on the host, with the semantics of
+// `docker cp`, like CopyPathToContainer. The mode of the files is preserved.
+func (cli *Client) CopyPathFromContainer(ctx context.Context, container, srcPath, dstPath string, options types.CopyPathOptions) error {
+	var rebaseName string
+	if options.FollowLink {
+		srcStat, err := cli.ContainerStatPath(ctx, container, srcPath)
+		if err != nil {
+			return err
+		}
+		if srcStat.Mode&os.ModeSymlink != 0 {
+			// The target of the link is copied under the name of the link.
+			srcPath, rebaseName = archive.GetRebaseName(srcPath, resolveLinkTarget(srcPath, srcStat.LinkTarget))
+		}
+	}
+
+	content, stat, err := cli.CopyFromContainer(ctx, container, srcPath)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	srcInfo := archive.CopyInfo{
+		Path:       srcPath,
+		Exists:     true,
+		IsDir:      stat.Mode.IsDir(),
+		RebaseName: rebaseName,
+	}
+	var preparedArchive io.ReadCloser = content
+	if rebaseName != "" {
+		_, srcBase := archive.SplitPathDirEntry(srcPath)
+		preparedArchive = archive.RebaseArchiveEntries(content, srcBase, rebaseName)
+		defer preparedArchive.Close()
+	}
+
+	return archive.CopyTo(preparedArchive, srcInfo, dstPath, archive.ExtractOptions{
+		NoOverwriteDirNonDir: true,
+		PreserveOwnership:    options.CopyUIDGID,
+	})
+}
+
+// resolveLinkTarget returns the path of the target of a symbolic link
+// in a container, relative to the directory of the link.
+func resolveLinkTarget(linkPath, linkTarget string) string {
+	if filepath.IsAbs(linkTarget) || len(linkTarget) > 0 && linkTarget[0] == '/' {
+		return linkTarget
+	}
+	dir, _ := archive.SplitPathDirEntry(linkPath)
+	return filepath.Join(dir, linkTarget)
+}
@@ -0,0 +1,345 @@
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/engine-api/archive"
+	"github.com/docker/engine-api/types"
+)
+
+// containerFSMock is a mock daemon serving the archive endpoints
+// with the filesystem of a container rooted at a directory.
+type containerFSMock struct {
+	root string
+	// puts are the queries of the requests extracting archives.
+	puts []string
+}
+
+// hostPath returns the path on the host of a path in the container,
+// keeping the trailing separator or dot that changes its meaning.
+func (m *containerFSMock) hostPath(path string) string {
+	hostPath := filepath.Join(m.root, filepath.FromSlash(path))
+	switch {
+	case strings.HasSuffix(path, "/."):
+		hostPath += string(filepath.Separator) + "."
+	case strings.HasSuffix(path, "/") && hostPath != m.root:
+		hostPath += string(filepath.Separator)
+	}
+	return hostPath
+}
+
+func (m *containerFSMock) do(req *http.Request) (*http.Response, error) {
+	if !strings.HasSuffix(req.URL.Path, "/containers/container_id/archive") {
+		return errorMock(http.StatusNotFound, "No such container: unknown")(req)
+	}
+	path := m.hostPath(req.URL.Query().Get("path"))
+
+	if req.Method == "PUT" {
+		m.puts = append(m.puts, req.URL.RawQuery)
+		noOverwrite := req.URL.Query().Get("noOverwriteDirNonDir") == "true"
+		if err := archive.Untar(req.Body, path, archive.ExtractOptions{NoOverwriteDirNonDir: noOverwrite}); err != nil {
+			return errorMock(http.StatusInternalServerError, err.Error())(req)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(nil))}, nil
+	}
+
+	fi, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return errorMock(http.StatusNotFound, "Could not find the file in container")(req)
+	}
+	if err != nil {
+		return errorMock(http.StatusInternalServerError, err.Error())(req)
+	}
+	stat := types.ContainerPathStat{Name: fi.Name(), Size: fi.Size(), Mode: fi.Mode(), Mtime: fi.ModTime()}
+	if fi.Mode()&os.ModeSymlink != 0 {
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(m.root, target)
+		if err != nil {
+			return nil, err
+		}
+		stat.LinkTarget = "/" + filepath.ToSlash(rel)
+	}
+	encodedStat, err := json.Marshal(stat)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	header.Set("X-Docker-Container-Path-Stat", base64.StdEncoding.EncodeToString(encodedStat))
+
+	body := ioutil.NopCloser(bytes.NewReader(nil))
+	if req.Method == "GET" {
+		srcInfo, err := archive.CopyInfoSourcePath(path, false)
+		if err != nil {
+			return errorMock(http.StatusInternalServerError, err.Error())(req)
+		}
+		if body, err = archive.TarResource(srcInfo); err != nil {
+			return nil, err
+		}
+	}
+	return &http.Response{StatusCode: http.StatusOK, Header: header, Body: body}, nil
+}
+
+// createCopyTree creates the files copied in the tests in the directory.
+func createCopyTree(t *testing.T, dir string) {
+	files := []struct {
+		path, content string
+	}{
+		{"file1", "file1\n"},
+		{"dir1/file2", "file2\n"},
+		{"dir1/sub/file3", "file3\n"},
+		{"existing-file", "old\n"},
+		{"existing-dir/keep", "keep\n"},
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, filepath.FromSlash(f.path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(f.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := []struct {
+		path, target string
+	}{
+		{"link-file", "file1"},
+		{"link-dir", "dir1"},
+		{"link-existing-dir", "existing-dir"},
+	}
+	for _, l := range links {
+		if err := os.Symlink(l.target, filepath.Join(dir, l.path)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// listCopyTree returns the content of the files in the directory by their
+// path, the target of the symbolic links prefixed with "->", or "dir".
+func listCopyTree(t *testing.T, dir string) map[string]string {
+	tree := map[string]string{}
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		switch {
+		case fi.IsDir():
+			tree[rel] = "dir"
+		case fi.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			tree[rel] = "->" + target
+		default:
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			tree[rel] = string(content)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+var copyPathCases = []struct {
+	src, dst   string
+	followLink bool
+	// expected are the files created or changed in the destination.
+	expected      map[string]string
+	expectedError string
+}{
+	// The source is a file.
+	{src: "file1", dst: "new-file", expected: map[string]string{"new-file": "file1\n"}},
+	{src: "file1", dst: "new-dir/", expectedError: "no such directory"},
+	{src: "file1", dst: "existing-file", expected: map[string]string{"existing-file": "file1\n"}},
+	{src: "file1", dst: "existing-file/", expectedError: "not a directory"},
+	{src: "file1", dst: "existing-dir", expected: map[string]string{"existing-dir/file1": "file1\n"}},
+	{src: "file1", dst: "existing-dir/", expected: map[string]string{"existing-dir/file1": "file1\n"}},
+	{src: "file1", dst: "link-existing-dir", expected: map[string]string{"existing-dir/file1": "file1\n"}},
+	{src: "file1", dst: "missing/new-file", expectedError: "no such file or directory"},
+	{src: "file1/", dst: "new-file", expectedError: "not a directory"},
+
+	// The source is a directory.
+	{src: "dir1", dst: "new-dir", expected: map[string]string{"new-dir": "dir", "new-dir/file2": "file2\n", "new-dir/sub": "dir", "new-dir/sub/file3": "file3\n"}},
+	{src: "dir1", dst: "new-dir/", expected: map[string]string{"new-dir": "dir", "new-dir/file2": "file2\n", "new-dir/sub": "dir", "new-dir/sub/file3": "file3\n"}},
+	{src: "dir1/", dst: "new-dir", expected: map[string]string{"new-dir": "dir", "new-dir/file2": "file2\n", "new-dir/sub": "dir", "new-dir/sub/file3": "file3\n"}},
+	{src: "dir1", dst: "existing-file", expectedError: "cannot copy directory"},
+	{src: "dir1", dst: "existing-dir", expected: map[string]string{"existing-dir/dir1": "dir", "existing-dir/dir1/file2": "file2\n", "existing-dir/dir1/sub": "dir", "existing-dir/dir1/sub/file3": "file3\n"}},
+	{src: "dir1", dst: "link-existing-dir", expected: map[string]string{"existing-dir/dir1": "dir", "existing-dir/dir1/file2": "file2\n", "existing-dir/dir1/sub": "dir", "existing-dir/dir1/sub/file3": "file3\n"}},
+
+	// The source is the content of a directory.
+	{src: "dir1/.", dst: "new-dir", expected: map[string]string{"new-dir": "dir", "new-dir/file2": "file2\n", "new-dir/sub": "dir", "new-dir/sub/file3": "file3\n"}},
+	{src: "dir1/.", dst: "existing-file", expectedError: "cannot copy directory"},
+	{src: "dir1/.", dst: "existing-dir", expected: map[string]string{"existing-dir/file2": "file2\n", "existing-dir/sub": "dir", "existing-dir/sub/file3": "file3\n"}},
+
+	// The source is a symbolic link.
+	{src: "link-file", dst: "new-file", expected: map[string]string{"new-file": "->file1"}},
+	{src: "link-file", dst: "existing-dir", expected: map[string]string{"existing-dir/link-file": "->file1"}},
+	{src: "link-file", dst: "new-file", followLink: true, expected: map[string]string{"new-file": "file1\n"}},
+	{src: "link-file", dst: "existing-dir", followLink: true, expected: map[string]string{"existing-dir/link-file": "file1\n"}},
+	{src: "link-dir", dst: "existing-dir", followLink: true, expected: map[string]string{"existing-dir/link-dir": "dir", "existing-dir/link-dir/file2": "file2\n", "existing-dir/link-dir/sub": "dir", "existing-dir/link-dir/sub/file3": "file3\n"}},
+	{src: "link-dir/", dst: "existing-dir", expected: map[string]string{"existing-dir/link-dir": "dir", "existing-dir/link-dir/file2": "file2\n", "existing-dir/link-dir/sub": "dir", "existing-dir/link-dir/sub/file3": "file3\n"}},
+	{src: "link-dir/.", dst: "existing-dir", expected: map[string]string{"existing-dir/file2": "file2\n", "existing-dir/sub": "dir", "existing-dir/sub/file3": "file3\n"}},
+}
+
+// checkCopyPath compares the destination tree to the expected changes.
+func checkCopyPath(t *testing.T, dstRoot string, src, dst string, expected map[string]string, expectedError string, err error) {
+	if expectedError != "" {
+		if err == nil || !strings.Contains(err.Error(), expectedError) {
+			t.Fatalf("copying %s to %s: expected an error containing %q, got %v", src, dst, expectedError, err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("copying %s to %s: %v", src, dst, err)
+	}
+
+	initialDir, err := ioutil.TempDir("", "copy-initial")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(initialDir)
+	createCopyTree(t, initialDir)
+	tree := listCopyTree(t, initialDir)
+	for path, content := range expected {
+		tree[path] = content
+	}
+	if actual := listCopyTree(t, dstRoot); !reflect.DeepEqual(actual, tree) {
+		t.Fatalf("copying %s to %s: expected %v, got %v", src, dst, tree, actual)
+	}
+}
+
+func TestCopyPathToContainer(t *testing.T) {
+	for _, c := range copyPathCases {
+		srcRoot, err := ioutil.TempDir("", "copy-src")
+		if err != nil {
+			t.Fatal(err)
+		}
+		dstRoot, err := ioutil.TempDir("", "copy-dst")
+		if err != nil {
+			t.Fatal(err)
+		}
+		createCopyTree(t, srcRoot)
+		createCopyTree(t, dstRoot)
+
+		mock := &containerFSMock{root: dstRoot}
+		client := &Client{transport: newMockClient(nil, mock.do)}
+		err = client.CopyPathToContainer(context.Background(), "container_id", srcRoot+"/"+c.src, "/"+c.dst, types.CopyPathOptions{FollowLink: c.followLink})
+		checkCopyPath(t, dstRoot, c.src, c.dst, c.expected, c.expectedError, err)
+
+		os.RemoveAll(srcRoot)
+		os.RemoveAll(dstRoot)
+	}
+}
+
+func TestCopyPathFromContainer(t *testing.T) {
+	for _, c := range copyPathCases {
+		srcRoot, err := ioutil.TempDir("", "copy-src")
+		if err != nil {
+			t.Fatal(err)
+		}
+		dstRoot, err := ioutil.TempDir("", "copy-dst")
+		if err != nil {
+			t.Fatal(err)
+		}
+		createCopyTree(t, srcRoot)
+		createCopyTree(t, dstRoot)
+
+		mock := &containerFSMock{root: srcRoot}
+		client := &Client{transport: newMockClient(nil, mock.do)}
+		err = client.CopyPathFromContainer(context.Background(), "container_id", "/"+c.src, dstRoot+"/"+c.dst, types.CopyPathOptions{FollowLink: c.followLink})
+		checkCopyPath(t, dstRoot, c.src, c.dst, c.expected, c.expectedError, err)
+
+		os.RemoveAll(srcRoot)
+		os.RemoveAll(dstRoot)
+	}
+}
+
+func TestCopyPathPreservesMode(t *testing.T) {
+	srcRoot, err := ioutil.TempDir("", "copy-src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(srcRoot)
+	dstRoot, err := ioutil.TempDir("", "copy-dst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dstRoot)
+	createCopyTree(t, srcRoot)
+	if err := os.Chmod(filepath.Join(srcRoot, "dir1", "file2"), 0751); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(srcRoot, "dir1"), 0705); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := &containerFSMock{root: dstRoot}
+	client := &Client{transport: newMockClient(nil, mock.do)}
+	options := types.CopyPathOptions{CopyUIDGID: true}
+	if err := client.CopyPathToContainer(context.Background(), "container_id", filepath.Join(srcRoot, "dir1"), "/dir", options); err != nil {
+		t.Fatal(err)
+	}
+	expectedQuery := "copyUIDGID=true&noOverwriteDirNonDir=true&path=%2F"
+	if len(mock.puts) != 1 || mock.puts[0] != expectedQuery {
+		t.Fatalf("expected the archive to be extracted with %s, got %v", expectedQuery, mock.puts)
+	}
+
+	hostDir := filepath.Join(dstRoot, "copy")
+	if err := client.CopyPathFromContainer(context.Background(), "container_id", "/dir", hostDir, options); err != nil {
+		t.Fatal(err)
+	}
+	for path, mode := range map[string]os.FileMode{"dir": 0705 | os.ModeDir, "copy": 0705 | os.ModeDir, "copy/file2": 0751} {
+		fi, err := os.Stat(filepath.Join(dstRoot, filepath.FromSlash(path)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode() != mode {
+			t.Fatalf("expected %s to have the mode %s, got %s", path, mode, fi.Mode())
+		}
+	}
+}
+
+func TestCopyPathErrors(t *testing.T) {
+	client := &Client{
+		transport: newMockClient(nil, errorMock(http.StatusInternalServerError, "Server error")),
+	}
+	err := client.CopyPathToContainer(context.Background(), "container_id", "file", "/file", types.CopyPathOptions{})
+	if err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server error, got %v", err)
+	}
+	err = client.CopyPathFromContainer(context.Background(), "container_id", "/file", "file", types.CopyPathOptions{})
+	if err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server error, got %v", err)
+	}
+
+	mock := &containerFSMock{root: os.TempDir()}
+	client = &Client{transport: newMockClient(nil, mock.do)}
+	err = client.CopyPathFromContainer(context.Background(), "unknown", "/file", "file", types.CopyPathOptions{})
+	if !IsErrNotFound(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
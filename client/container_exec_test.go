@@ -155,3 +155,30 @@ func TestContainerExecInspect(t *testing.T) {
 		t.Fatalf("expected ContainerID `container_id`, got %s", inspect.ContainerID)
 	}
 }
+
+func TestContainerExecInspectDaemonResponse(t *testing.T) {
+	client := &Client{
+		transport: newMockClient(nil, func(req *http.Request) (*http.Response, error) {
+			body := `{"ID":"exec_id","Running":false,"ExitCode":2,"ProcessConfig":{"tty":true,"entrypoint":"sh","arguments":["-c","exit 2"],"privileged":false,"user":"nobody"},"OpenStdin":true,"OpenStderr":false,"OpenStdout":true,"CanRemove":true,"ContainerID":"container_id","DetachKeys":"","Pid":42}`
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(body)),
+			}, nil
+		}),
+	}
+
+	inspect, err := client.ContainerExecInspect(context.Background(), "exec_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inspect.ExecID != "exec_id" || inspect.ExitCode != 2 || inspect.Pid != 42 {
+		t.Fatalf("unexpected exec: %+v", inspect)
+	}
+	if !inspect.OpenStdin || !inspect.OpenStdout || inspect.OpenStderr || !inspect.CanRemove {
+		t.Fatalf("unexpected streams: %+v", inspect)
+	}
+	process := inspect.ProcessConfig
+	if !process.Tty || process.Entrypoint != "sh" || len(process.Arguments) != 2 || process.User != "nobody" || process.Privileged == nil || *process.Privileged {
+		t.Fatalf("unexpected process: %+v", process)
+	}
+}
@@ -16,7 +16,7 @@ func (cli *Client) ContainerInspect(ctx context.Context, containerID string) (ty
 	serverResp, err := cli.get(ctx, "/containers/"+containerID+"/json", nil, nil)
 	if err != nil {
 		if serverResp.statusCode == http.StatusNotFound {
-			return types.ContainerJSON{}, containerNotFoundError{containerID}
+			return types.ContainerJSON{}, containerNotFoundError{containerID, err}
 		}
 		return types.ContainerJSON{}, err
 	}
@@ -36,7 +36,7 @@ func (cli *Client) ContainerInspectWithRaw(ctx context.Context, containerID stri
 	serverResp, err := cli.get(ctx, "/containers/"+containerID+"/json", query, nil)
 	if err != nil {
 		if serverResp.statusCode == http.StatusNotFound {
-			return types.ContainerJSON{}, nil, containerNotFoundError{containerID}
+			return types.ContainerJSON{}, nil, containerNotFoundError{containerID, err}
 		}
 		return types.ContainerJSON{}, nil, err
 	}
@@ -35,7 +35,7 @@ func (cli *Client) ContainerList(ctx context.Context, options types.ContainerLis
 	}
 
 	if options.Filter.Len() > 0 {
-		filterJSON, err := filters.ToParamWithVersion(cli.version, options.Filter)
+		filterJSON, err := filters.ToParamWithVersion(cli.ClientVersion(), options.Filter)
 
 		if err != nil {
 			return nil, err
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"strings"
+
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
+	"golang.org/x/net/context"
+)
+
+// ContainersPrune removes the containers that aren't running, and reports
+// the space reclaimed. The "until" filter only prunes the containers created
+// before a timestamp or duration, and the "label" and "label!" filters the
+// containers with, or without, the given labels.
+//
+// The containers are removed one by one when the daemon doesn't support
+// the prune endpoint or the filters.
+func (cli *Client) ContainersPrune(ctx context.Context, pruneFilters filters.Args) (types.ContainersPruneReport, error) {
+	var report types.ContainersPruneReport
+	if ok, err := cli.prune(ctx, "/containers/prune", pruneFilters, &report); ok || err != nil {
+		return report, err
+	}
+	return cli.pruneContainers(ctx, pruneFilters)
+}
+
+// pruneContainers prunes the containers on the client side.
+func (cli *Client) pruneContainers(ctx context.Context, pruneFilters filters.Args) (types.ContainersPruneReport, error) {
+	var report types.ContainersPruneReport
+	f, err := newPruneFilter(pruneFilters, map[string]bool{"until": true, "label": true, "label!": true})
+	if err != nil {
+		return report, err
+	}
+
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{All: true, Size: true})
+	if err != nil {
+		return report, err
+	}
+	for _, c := range containers {
+		if isContainerRunning(c) || !f.match(c.Created, c.Labels) {
+			continue
+		}
+		if err := cli.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{}); err != nil {
+			if isPruneSkipped(err) {
+				continue
+			}
+			return report, err
+		}
+		report.ContainersDeleted = append(report.ContainersDeleted, c.ID)
+		report.SpaceReclaimed += uint64(c.SizeRw)
+	}
+	return report, nil
+}
+
+// isContainerRunning returns whether the container is running, paused or
+// restarting. Daemons prior to API 1.23 only report its status, like "Up 2 hours".
+func isContainerRunning(c types.Container) bool {
+	switch c.State {
+	case "running", "paused", "restarting":
+		return true
+	case "":
+		return strings.HasPrefix(c.Status, "Up") || strings.HasPrefix(c.Status, "Restarting")
+	}
+	return false
+}
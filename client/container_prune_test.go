@@ -0,0 +1,128 @@
+package client
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
+	"golang.org/x/net/context"
+)
+
+func TestContainersPruneError(t *testing.T) {
+	client := &Client{
+		transport: newMockClient(nil, errorMock(http.StatusInternalServerError, "Server error")),
+		version:   "1.25",
+	}
+
+	_, err := client.ContainersPrune(context.Background(), filters.NewArgs())
+	if err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+}
+
+func TestContainersPrune(t *testing.T) {
+	pruneFilters := filters.NewArgs()
+	pruneFilters.Add("label", "env=test")
+
+	var filterJSON string
+	client := &Client{
+		transport: newMockClient(nil, func(req *http.Request) (*http.Response, error) {
+			filterJSON = req.URL.Query().Get("filters")
+			return pruneMock(map[string]interface{}{
+				"POST /v1.28/containers/prune": types.ContainersPruneReport{
+					ContainersDeleted: []string{"container_id1", "container_id2"},
+					SpaceReclaimed:    1024,
+				},
+			}, new([]string))(req)
+		}),
+		version: "1.28",
+	}
+
+	report, err := client.ContainersPrune(context.Background(), pruneFilters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filterJSON != `{"label":{"env=test":true}}` {
+		t.Fatalf("expected the label filter, got %s", filterJSON)
+	}
+	if !reflect.DeepEqual(report.ContainersDeleted, []string{"container_id1", "container_id2"}) || report.SpaceReclaimed != 1024 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
+
+func TestContainersPruneFallback(t *testing.T) {
+	containers := []types.Container{
+		{ID: "running", State: "running", Created: 100},
+		{ID: "paused", State: "paused", Created: 100},
+		{ID: "exited", State: "exited", Created: 100, SizeRw: 10},
+		{ID: "created", State: "created", Created: 100, SizeRw: 20},
+		{ID: "recent", State: "exited", Created: 300, SizeRw: 40},
+		{ID: "started", State: "exited", Created: 100, SizeRw: 80},
+		{ID: "up", Status: "Up 2 hours", Created: 100},
+		{ID: "stopped", Status: "Exited (0) 2 hours ago", Created: 100, SizeRw: 160},
+	}
+	pruneFilters := filters.NewArgs()
+	pruneFilters.Add("until", "200")
+
+	cases := []struct {
+		version  string
+		requests []string
+	}{
+		{
+			version: "1.24",
+			requests: []string{
+				"GET /v1.24/containers/json",
+				"DELETE /v1.24/containers/exited",
+				"DELETE /v1.24/containers/created",
+				"DELETE /v1.24/containers/started",
+				"DELETE /v1.24/containers/stopped",
+			},
+		},
+		{
+			version: "",
+			requests: []string{
+				"POST /containers/prune",
+				"GET /containers/json",
+				"DELETE /containers/exited",
+				"DELETE /containers/created",
+				"DELETE /containers/started",
+				"DELETE /containers/stopped",
+			},
+		},
+	}
+	for _, c := range cases {
+		prefix := ""
+		if c.version != "" {
+			prefix = "/v" + c.version
+		}
+		var requests []string
+		client := &Client{
+			transport: newMockClient(nil, pruneMock(map[string]interface{}{
+				"GET " + prefix + "/containers/json":       containers,
+				"DELETE " + prefix + "/containers/exited":  "",
+				"DELETE " + prefix + "/containers/created": "",
+				// The container was started since it was listed.
+				"DELETE " + prefix + "/containers/started": http.StatusConflict,
+				"DELETE " + prefix + "/containers/stopped": "",
+			}, &requests)),
+			version: c.version,
+		}
+
+		report, err := client.ContainersPrune(context.Background(), pruneFilters)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(requests, c.requests) {
+			t.Fatalf("expected requests %v, got %v", c.requests, requests)
+		}
+		expected := types.ContainersPruneReport{
+			ContainersDeleted: []string{"exited", "created", "stopped"},
+			SpaceReclaimed:    190,
+		}
+		if !reflect.DeepEqual(report, expected) {
+			t.Fatalf("expected report %+v, got %+v", expected, report)
+		}
+	}
+}
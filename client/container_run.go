@@ -0,0 +1,110 @@
+package client
+
+import (
+	"io"
+	"io/ioutil"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/engine-api/stdcopy"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/container"
+	"github.com/docker/engine-api/types/network"
+)
+
+// ContainerRun creates a container, runs it until it exits and returns
+// its exit code. The streams of the options are attached to the container
+// before it starts, so no output is lost, and its output is split
+// into stdout and stderr when there is no TTY attached.
+//
+// If the host configuration requests the auto removal of the container,
+// the client removes it once it exits, or when the context is done,
+// killing it first. Otherwise the container keeps running when the
+// context is done.
+func (cli *Client) ContainerRun(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, options types.ContainerRunOptions) (int, error) {
+	autoRemove := hostConfig != nil && hostConfig.AutoRemove
+	if autoRemove {
+		// The daemon would remove the container before its exit code
+		// can be read, so the client removes it instead.
+		hc := *hostConfig
+		hc.AutoRemove = false
+		hostConfig = &hc
+	}
+
+	created, err := cli.ContainerCreate(ctx, config, hostConfig, networkingConfig, options.Name)
+	if err != nil {
+		return -1, err
+	}
+	containerID := created.ID
+	cleanup := func(kill bool) {
+		if !autoRemove {
+			return
+		}
+		// The context may be done already.
+		if kill {
+			cli.ContainerKill(context.Background(), containerID, "KILL")
+		}
+		cli.ContainerRemove(context.Background(), containerID, types.ContainerRemoveOptions{Force: true})
+	}
+
+	outputDone := make(chan error, 1)
+	if options.Stdin != nil || options.Stdout != nil || options.Stderr != nil {
+		resp, err := cli.ContainerAttach(ctx, containerID, types.ContainerAttachOptions{
+			Stream: true,
+			Stdin:  options.Stdin != nil,
+			Stdout: options.Stdout != nil,
+			Stderr: options.Stderr != nil,
+		})
+		if err != nil {
+			cleanup(false)
+			return -1, err
+		}
+		defer resp.Close()
+
+		if options.Stdin != nil {
+			go func() {
+				io.Copy(resp.Conn, options.Stdin)
+				resp.CloseWrite()
+			}()
+		}
+		stdout, stderr := options.Stdout, options.Stderr
+		if stdout == nil {
+			stdout = ioutil.Discard
+		}
+		if stderr == nil {
+			stderr = ioutil.Discard
+		}
+		go func() {
+			_, err := stdcopy.Copy(stdout, stderr, resp.Reader, !stdcopy.IsMultiplexed(config))
+			outputDone <- err
+		}()
+	} else {
+		outputDone <- nil
+	}
+
+	if err := cli.ContainerStart(ctx, containerID, types.ContainerStartOptions{}); err != nil {
+		cleanup(ctx.Err() != nil)
+		return -1, err
+	}
+
+	select {
+	case err = <-outputDone:
+	case <-ctx.Done():
+	}
+	if ctx.Err() != nil {
+		cleanup(true)
+		return -1, ctx.Err()
+	}
+	if err != nil {
+		cleanup(true)
+		return -1, err
+	}
+
+	statusCode, err := cli.ContainerWait(ctx, containerID)
+	if err != nil {
+		cleanup(ctx.Err() != nil)
+		return -1, err
+	}
+	cleanup(false)
+	return statusCode, nil
+}
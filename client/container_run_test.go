@@ -0,0 +1,180 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/engine-api/stdcopy"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/container"
+)
+
+// runMock is a mock daemon that runs a container writing output
+// to the attached streams, and records the requests it receives.
+type runMock struct {
+	mu       sync.Mutex
+	requests []string
+	// exit is closed when the container exits.
+	exit chan struct{}
+}
+
+func (m *runMock) record(request string) {
+	m.mu.Lock()
+	m.requests = append(m.requests, request)
+	m.mu.Unlock()
+}
+
+func (m *runMock) recorded() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return strings.Join(m.requests, " ")
+}
+
+func (m *runMock) client() *Client {
+	return &Client{
+		version: "1.24",
+		transport: dialerMock{
+			Client: newMockClient(nil, m.do),
+			dial:   m.dial,
+		},
+	}
+}
+
+func (m *runMock) do(req *http.Request) (*http.Response, error) {
+	path := strings.TrimPrefix(req.URL.Path, "/v1.24")
+	m.record(req.Method + " " + path)
+
+	var body string
+	switch {
+	case path == "/containers/create":
+		var config struct {
+			HostConfig container.HostConfig
+		}
+		if err := json.NewDecoder(req.Body).Decode(&config); err != nil {
+			return nil, err
+		}
+		if config.HostConfig.AutoRemove {
+			return nil, fmt.Errorf("expected the client to remove the container")
+		}
+		body = `{"Id":"container_id"}`
+	case path == "/containers/container_id/wait":
+		<-m.exit
+		body = `{"StatusCode":3}`
+	case path == "/containers/container_id/start":
+		close(m.exit)
+	case path == "/containers/container_id/kill" || path == "/containers/container_id":
+	default:
+		return nil, fmt.Errorf("unexpected request to %s", path)
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+	}, nil
+}
+
+func (m *runMock) dial() (net.Conn, error) {
+	conn, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		br := bufio.NewReader(server)
+		req, err := http.ReadRequest(br)
+		if err != nil {
+			return
+		}
+		m.record(req.Method + " " + strings.TrimPrefix(req.URL.Path, "/v1.24"))
+		fmt.Fprint(server, "HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.raw-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+
+		// Read the input before the container starts.
+		input, err := br.ReadString('\n')
+		if err != nil {
+			return
+		}
+		<-m.exit
+		fmt.Fprintf(stdcopy.NewStdWriter(server, stdcopy.Stdout), "read %s", input)
+		fmt.Fprint(stdcopy.NewStdWriter(server, stdcopy.Stderr), "warning\n")
+	}()
+	return conn, nil
+}
+
+func TestContainerRun(t *testing.T) {
+	mock := &runMock{exit: make(chan struct{})}
+	client := mock.client()
+
+	var stdout, stderr bytes.Buffer
+	statusCode, err := client.ContainerRun(context.Background(), &container.Config{Image: "busybox", OpenStdin: true}, &container.HostConfig{AutoRemove: true}, nil, types.ContainerRunOptions{
+		Stdin:  strings.NewReader("input\n"),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statusCode != 3 {
+		t.Fatalf("expected the exit code of the container, got %d", statusCode)
+	}
+	if stdout.String() != "read input\n" || stderr.String() != "warning\n" {
+		t.Fatalf("expected the output to be split, got %q and %q", stdout.String(), stderr.String())
+	}
+	expected := "POST /containers/create POST /containers/container_id/attach POST /containers/container_id/start POST /containers/container_id/wait DELETE /containers/container_id"
+	if mock.recorded() != expected {
+		t.Fatalf("expected the requests %s, got %s", expected, mock.recorded())
+	}
+}
+
+func TestContainerRunWithoutStreams(t *testing.T) {
+	mock := &runMock{exit: make(chan struct{})}
+	client := mock.client()
+
+	statusCode, err := client.ContainerRun(context.Background(), &container.Config{Image: "busybox"}, nil, nil, types.ContainerRunOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statusCode != 3 {
+		t.Fatalf("expected the exit code of the container, got %d", statusCode)
+	}
+	expected := "POST /containers/create POST /containers/container_id/start POST /containers/container_id/wait"
+	if mock.recorded() != expected {
+		t.Fatalf("expected the requests %s, got %s", expected, mock.recorded())
+	}
+}
+
+func TestContainerRunCanceled(t *testing.T) {
+	// The container never exits.
+	mock := &runMock{exit: make(chan struct{})}
+	client := mock.client()
+	client.transport = dialerMock{
+		Client: newMockClient(nil, func(req *http.Request) (*http.Response, error) {
+			if strings.HasSuffix(req.URL.Path, "/start") {
+				mock.record("POST /containers/container_id/start")
+				return &http.Response{StatusCode: http.StatusNoContent, Body: ioutil.NopCloser(bytes.NewReader(nil))}, nil
+			}
+			return mock.do(req)
+		}),
+		dial: mock.dial,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := client.ContainerRun(ctx, &container.Config{Image: "busybox", OpenStdin: true}, &container.HostConfig{AutoRemove: true}, nil, types.ContainerRunOptions{
+		Stdin:  strings.NewReader("input\n"),
+		Stdout: ioutil.Discard,
+	})
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected the deadline to be exceeded, got %v", err)
+	}
+	expected := "POST /containers/create POST /containers/container_id/attach POST /containers/container_id/start POST /containers/container_id/kill DELETE /containers/container_id"
+	if mock.recorded() != expected {
+		t.Fatalf("expected the requests %s, got %s", expected, mock.recorded())
+	}
+}
@@ -1,10 +1,15 @@
 package client
 
 import (
+	"encoding/json"
 	"io"
 	"net/url"
 
 	"golang.org/x/net/context"
+
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/versions"
+	"github.com/docker/engine-api/types/versions/v1p20"
 )
 
 // ContainerStats returns near realtime stats for a given container.
@@ -22,3 +27,74 @@ func (cli *Client) ContainerStats(ctx context.Context, containerID string, strea
 	}
 	return resp.body, err
 }
+
+// ContainerStatsSamples returns the stats of a given container decoded,
+// along with the metrics derived from each sample.
+// When stream is false, the daemon sends a single sample.
+// The samples channel is closed when the stats end, the error, if any,
+// is sent to the errors channel before it's closed.
+func (cli *Client) ContainerStatsSamples(ctx context.Context, containerID string, stream bool) (<-chan types.ContainerStatsSample, <-chan error) {
+	samples := make(chan types.ContainerStatsSample)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(samples)
+
+		body, err := cli.ContainerStats(ctx, containerID, stream)
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer body.Close()
+
+		dec := json.NewDecoder(body)
+		var previous *types.StatsJSON
+		for {
+			stats, err := cli.decodeStats(dec)
+			if err != nil {
+				if ctx.Err() != nil {
+					err = ctx.Err()
+				}
+				if err != io.EOF {
+					errs <- err
+				}
+				return
+			}
+
+			sample := types.ContainerStatsSample{
+				Stats:   stats,
+				Summary: stats.Summarize(previous),
+			}
+			select {
+			case samples <- sample:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+			previous = &stats
+		}
+	}()
+
+	return samples, errs
+}
+
+// decodeStats decodes the next stats sample.
+// Daemons prior to API 1.21 report a single network,
+// it's returned as the network of the eth0 interface.
+func (cli *Client) decodeStats(dec *json.Decoder) (types.StatsJSON, error) {
+	if version := cli.ClientVersion(); version != "" && versions.LessThan(version, "1.21") {
+		var v v1p20.StatsJSON
+		if err := dec.Decode(&v); err != nil {
+			return types.StatsJSON{}, err
+		}
+		return types.StatsJSON{
+			Stats:    v.Stats,
+			Networks: map[string]types.NetworkStats{"eth0": v.Network},
+		}, nil
+	}
+
+	var stats types.StatsJSON
+	err := dec.Decode(&stats)
+	return stats, err
+}
@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"golang.org/x/net/context"
+
+	"github.com/docker/engine-api/types"
 )
 
 func TestContainerStatsError(t *testing.T) {
@@ -68,3 +70,107 @@ func TestContainerStats(t *testing.T) {
 		}
 	}
 }
+
+func TestContainerStatsSamplesError(t *testing.T) {
+	client := &Client{
+		transport: newMockClient(nil, errorMock(http.StatusInternalServerError, "Server error")),
+	}
+	samples, errs := client.ContainerStatsSamples(context.Background(), "nothing", false)
+	for range samples {
+		t.Fatal("expected no samples")
+	}
+	if err := <-errs; err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+}
+
+func TestContainerStatsSamples(t *testing.T) {
+	const statsStream = `{"precpu_stats":{"cpu_usage":{"total_usage":100,"percpu_usage":[50,50]},"system_cpu_usage":1000},` +
+		`"cpu_stats":{"cpu_usage":{"total_usage":200,"percpu_usage":[100,100]},"system_cpu_usage":1400},` +
+		`"memory_stats":{"usage":300,"limit":1000,"stats":{"cache":100}},` +
+		`"blkio_stats":{"io_service_bytes_recursive":[{"op":"Read","value":10},{"op":"Write","value":20},{"op":"Total","value":30}]},` +
+		`"pids_stats":{"current":3},` +
+		`"networks":{"eth0":{"rx_bytes":100,"tx_bytes":10},"eth1":{"rx_bytes":50,"tx_bytes":5}}}
+{"cpu_stats":{"cpu_usage":{"total_usage":300}},` +
+		`"blkio_stats":{"io_service_bytes_recursive":[{"op":"Read","value":15},{"op":"Write","value":40}]},` +
+		`"networks":{"eth0":{"rx_bytes":200,"tx_bytes":20},"eth1":{"rx_bytes":50,"tx_bytes":5}}}
+`
+	client := &Client{
+		transport: newMockClient(nil, func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(statsStream)),
+			}, nil
+		}),
+	}
+
+	samples, errs := client.ContainerStatsSamples(context.Background(), "container_id", true)
+	var summaries []types.StatsSummary
+	for s := range samples {
+		summaries = append(summaries, s.Summary)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 2 {
+		t.Fatalf("expected 2 samples, got %d", len(summaries))
+	}
+
+	first := summaries[0]
+	if first.CPUPercentage != 50 {
+		t.Fatalf("expected 50%% CPU, got %v", first.CPUPercentage)
+	}
+	if first.MemoryUsage != 200 || first.MemoryLimit != 1000 || first.MemoryPercentage != 20 {
+		t.Fatalf("expected 200 bytes of 1000 memory used, got %+v", first)
+	}
+	if first.NetworkRx != 150 || first.NetworkTx != 15 || first.NetworkRxDelta != 0 {
+		t.Fatalf("expected network totals without deltas, got %+v", first)
+	}
+	if first.BlockRead != 10 || first.BlockWrite != 20 || first.PidsCurrent != 3 {
+		t.Fatalf("expected block totals and pids, got %+v", first)
+	}
+
+	second := summaries[1]
+	if second.CPUPercentage != 0 {
+		t.Fatalf("expected 0%% CPU without system usage, got %v", second.CPUPercentage)
+	}
+	if second.NetworkRxDelta != 100 || second.NetworkTxDelta != 10 {
+		t.Fatalf("expected network deltas, got %+v", second)
+	}
+	if second.BlockReadDelta != 5 || second.BlockWriteDelta != 20 {
+		t.Fatalf("expected block deltas, got %+v", second)
+	}
+}
+
+func TestContainerStatsSamplesSingleNetwork(t *testing.T) {
+	client := &Client{
+		version: "1.20",
+		transport: newMockClient(nil, func(r *http.Request) (*http.Response, error) {
+			if stream := r.URL.Query().Get("stream"); stream != "0" {
+				return nil, fmt.Errorf("expected stream to be 0, got %s", stream)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"network":{"rx_bytes":42,"tx_bytes":24}}`)),
+			}, nil
+		}),
+	}
+
+	samples, errs := client.ContainerStatsSamples(context.Background(), "container_id", false)
+	sample, ok := <-samples
+	if !ok {
+		t.Fatalf("expected a sample, got %v", <-errs)
+	}
+	if sample.Stats.Networks["eth0"].RxBytes != 42 {
+		t.Fatalf("expected the network to be reported as eth0, got %v", sample.Stats.Networks)
+	}
+	if sample.Summary.NetworkRx != 42 || sample.Summary.NetworkTx != 24 {
+		t.Fatalf("expected network totals, got %+v", sample.Summary)
+	}
+	if _, ok := <-samples; ok {
+		t.Fatal("expected a single sample")
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+}
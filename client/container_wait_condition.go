@@ -0,0 +1,149 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/events"
+	"github.com/docker/engine-api/types/filters"
+)
+
+// defaultWaitPollInterval is the time between the inspections of a container
+// when the options don't set one.
+const defaultWaitPollInterval = time.Second
+
+// ContainerWaitCondition blocks until the container meets the condition
+// of the options, or the context is done, and returns its state.
+//
+// It inspects the container every time the daemon reports an event about it,
+// and every poll interval in case the events are missed, like the ones that
+// happen before the stream starts.
+//
+// Waiting for a healthy container fails if it has no health check, if it stops,
+// or if its health check fails too many times.
+func (cli *Client) ContainerWaitCondition(ctx context.Context, containerID string, options types.ContainerWaitOptions) (types.ContainerWaitResult, error) {
+	condition := options.Condition
+	if condition == "" {
+		condition = types.WaitConditionNotRunning
+	}
+	switch condition {
+	case types.WaitConditionNotRunning, types.WaitConditionNextExit, types.WaitConditionRunning, types.WaitConditionHealthy, types.WaitConditionRemoved:
+	default:
+		return types.ContainerWaitResult{}, fmt.Errorf("invalid wait condition `%s`", condition)
+	}
+	interval := options.PollInterval
+	if interval <= 0 {
+		interval = defaultWaitPollInterval
+	}
+
+	// Stop streaming the events when the wait ends.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	eventFilters := filters.NewArgs()
+	eventFilters.Add("type", events.ContainerEventType)
+	eventFilters.Add("container", containerID)
+	// A single pending event is enough to inspect the container again.
+	messages, _ := cli.EventsStream(ctx, types.EventsStreamOptions{
+		EventsOptions: types.EventsOptions{Filters: eventFilters},
+		BufferSize:    1,
+		Overflow:      types.EventsOverflowDropNewest,
+	})
+
+	waiter := containerWaiter{
+		condition:        condition,
+		maxFailingStreak: options.MaxFailingStreak,
+	}
+	for {
+		done, result, err := waiter.check(cli.ContainerInspect(ctx, containerID))
+		if err != nil && ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		if done || err != nil {
+			return result, err
+		}
+
+		select {
+		case _, ok := <-messages:
+			if !ok {
+				// Keep polling if the stream fails.
+				messages = nil
+			}
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return result, ctx.Err()
+		}
+	}
+}
+
+// containerWaiter checks whether the inspections of
+// a container meet a wait condition.
+type containerWaiter struct {
+	condition        types.WaitCondition
+	maxFailingStreak int
+
+	// finishedAt is the time the container last exited
+	// when it was first inspected.
+	finishedAt *string
+}
+
+func (w *containerWaiter) check(container types.ContainerJSON, err error) (bool, types.ContainerWaitResult, error) {
+	if w.condition == types.WaitConditionRemoved && IsErrContainerNotFound(err) {
+		return true, types.ContainerWaitResult{}, nil
+	}
+	if err != nil {
+		return false, types.ContainerWaitResult{}, err
+	}
+	if container.ContainerJSONBase == nil || container.State == nil {
+		return false, types.ContainerWaitResult{}, fmt.Errorf("the daemon didn't return the state of the container")
+	}
+
+	state := *container.State
+	result := types.ContainerWaitResult{
+		State:     &state,
+		ExitCode:  state.ExitCode,
+		OOMKilled: state.OOMKilled,
+	}
+	if state.Health != nil {
+		result.HealthLog = state.Health.Log
+	}
+
+	switch w.condition {
+	case types.WaitConditionNotRunning:
+		return !state.Running, result, nil
+	case types.WaitConditionNextExit:
+		// The container exited if the time it finished changed,
+		// even if it was restarted since.
+		if w.finishedAt == nil {
+			w.finishedAt = &state.FinishedAt
+			return false, result, nil
+		}
+		return state.FinishedAt != *w.finishedAt, result, nil
+	case types.WaitConditionRunning:
+		return state.Running, result, nil
+	case types.WaitConditionHealthy:
+		return checkHealthy(container.ID, state, w.maxFailingStreak, result)
+	}
+	return false, result, nil
+}
+
+func checkHealthy(containerID string, state types.ContainerState, maxFailingStreak int, result types.ContainerWaitResult) (bool, types.ContainerWaitResult, error) {
+	if !state.Running && !state.Restarting {
+		return false, result, fmt.Errorf("container %s is not running", containerID)
+	}
+	health := state.Health
+	if health == nil {
+		return false, result, fmt.Errorf("container %s has no health check", containerID)
+	}
+	switch {
+	case health.Status == types.Healthy:
+		return true, result, nil
+	case maxFailingStreak > 0 && health.FailingStreak >= maxFailingStreak:
+		return false, result, fmt.Errorf("container %s failed %d consecutive health checks", containerID, health.FailingStreak)
+	case maxFailingStreak == 0 && health.Status == types.Unhealthy:
+		return false, result, fmt.Errorf("container %s is unhealthy", containerID)
+	}
+	return false, result, nil
+}
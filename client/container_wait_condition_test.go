@@ -0,0 +1,285 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/events"
+)
+
+// waitDaemon is a mock daemon serving the state of a container
+// and streaming the events sent to it.
+type waitDaemon struct {
+	mu      sync.Mutex
+	state   *types.ContainerState
+	events  chan events.Message
+	streams chan struct{}
+	// inspections receives a value when the container is first inspected.
+	inspections chan struct{}
+	// noEvents makes the events endpoint fail.
+	noEvents bool
+}
+
+func newWaitDaemon(state types.ContainerState) *waitDaemon {
+	return &waitDaemon{
+		state:       &state,
+		events:      make(chan events.Message, 1),
+		streams:     make(chan struct{}, 1),
+		inspections: make(chan struct{}, 1),
+	}
+}
+
+// update changes the state of the container,
+// and sends the event if it's not empty.
+func (d *waitDaemon) update(action string, update func(state *types.ContainerState)) {
+	d.mu.Lock()
+	if update != nil {
+		update(d.state)
+	}
+	d.mu.Unlock()
+	if action != "" {
+		d.events <- testEvent(action, "container_id", time.Now().UnixNano())
+	}
+}
+
+func (d *waitDaemon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/events"):
+		if d.noEvents {
+			http.Error(w, "Server error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		writeEvents(w)
+		closed := w.(http.CloseNotifier).CloseNotify()
+		select {
+		case d.streams <- struct{}{}:
+		default:
+		}
+		for {
+			select {
+			case m := <-d.events:
+				writeEvents(w, m)
+			case <-closed:
+				return
+			}
+		}
+	case strings.HasSuffix(r.URL.Path, "/containers/container_id/json"):
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if d.state == nil {
+			http.Error(w, "No such container: container_id", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{ID: "container_id", State: d.state},
+		})
+		select {
+		case d.inspections <- struct{}{}:
+		default:
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// waitFor runs ContainerWaitCondition in the background, and returns once the
+// container is inspected and the events are streamed, with a poll interval
+// that doesn't trigger in the tests.
+func waitFor(t *testing.T, client *Client, daemon *waitDaemon, options types.ContainerWaitOptions) (<-chan types.ContainerWaitResult, <-chan error) {
+	if options.PollInterval == 0 {
+		options.PollInterval = time.Minute
+	}
+	results := make(chan types.ContainerWaitResult, 1)
+	errs := make(chan error, 1)
+	go func() {
+		result, err := client.ContainerWaitCondition(context.Background(), "container_id", options)
+		results <- result
+		errs <- err
+	}()
+	select {
+	case <-daemon.inspections:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the container to be inspected")
+	}
+	if !daemon.noEvents {
+		select {
+		case <-daemon.streams:
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected the events to be streamed")
+		}
+	}
+	return results, errs
+}
+
+func TestContainerWaitConditionRunning(t *testing.T) {
+	daemon := newWaitDaemon(types.ContainerState{Status: "created"})
+	server, client := newTestServerClient(t, daemon.ServeHTTP)
+	defer server.Close()
+
+	results, errs := waitFor(t, client, daemon, types.ContainerWaitOptions{Condition: types.WaitConditionRunning})
+	daemon.update("start", func(state *types.ContainerState) {
+		state.Status = "running"
+		state.Running = true
+	})
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if result := <-results; result.State == nil || result.State.Status != "running" {
+		t.Fatalf("expected a running container, got %+v", result)
+	}
+}
+
+func TestContainerWaitConditionNextExit(t *testing.T) {
+	daemon := newWaitDaemon(types.ContainerState{Status: "exited", FinishedAt: "2016-10-01T00:00:00Z"})
+	server, client := newTestServerClient(t, daemon.ServeHTTP)
+	defer server.Close()
+
+	results, errs := waitFor(t, client, daemon, types.ContainerWaitOptions{Condition: types.WaitConditionNextExit})
+	// Starting the container again doesn't end the wait.
+	daemon.update("start", func(state *types.ContainerState) {
+		state.Running = true
+	})
+	daemon.update("die", func(state *types.ContainerState) {
+		state.Running = false
+		state.ExitCode = 137
+		state.OOMKilled = true
+		state.FinishedAt = "2016-10-02T00:00:00Z"
+	})
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if result := <-results; result.ExitCode != 137 || !result.OOMKilled {
+		t.Fatalf("expected the container to be killed, got %+v", result)
+	}
+}
+
+func TestContainerWaitConditionHealthy(t *testing.T) {
+	daemon := newWaitDaemon(types.ContainerState{Running: true, Health: &types.Health{Status: types.Starting}})
+	server, client := newTestServerClient(t, daemon.ServeHTTP)
+	defer server.Close()
+
+	results, errs := waitFor(t, client, daemon, types.ContainerWaitOptions{Condition: types.WaitConditionHealthy})
+	daemon.update("health_status: healthy", func(state *types.ContainerState) {
+		state.Health = &types.Health{
+			Status: types.Healthy,
+			Log:    []*types.HealthcheckResult{{ExitCode: 0, Output: "ok"}},
+		}
+	})
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if result := <-results; len(result.HealthLog) != 1 || result.HealthLog[0].Output != "ok" {
+		t.Fatalf("expected the health log, got %+v", result)
+	}
+}
+
+func TestContainerWaitConditionHealthyFailures(t *testing.T) {
+	cases := []struct {
+		state         types.ContainerState
+		update        func(state *types.ContainerState)
+		options       types.ContainerWaitOptions
+		expectedError string
+	}{
+		{
+			// The wait fails before the events are streamed.
+			state:         types.ContainerState{Running: true},
+			options:       types.ContainerWaitOptions{PollInterval: time.Minute},
+			expectedError: "container container_id has no health check",
+		},
+		{
+			state: types.ContainerState{Running: true, Health: &types.Health{Status: types.Starting}},
+			update: func(state *types.ContainerState) {
+				state.Running = false
+			},
+			expectedError: "container container_id is not running",
+		},
+		{
+			state: types.ContainerState{Running: true, Health: &types.Health{Status: types.Starting}},
+			update: func(state *types.ContainerState) {
+				state.Health.Status = types.Unhealthy
+				state.Health.FailingStreak = 3
+			},
+			expectedError: "container container_id is unhealthy",
+		},
+		{
+			state: types.ContainerState{Running: true, Health: &types.Health{Status: types.Starting}},
+			update: func(state *types.ContainerState) {
+				state.Health.FailingStreak = 2
+			},
+			options:       types.ContainerWaitOptions{MaxFailingStreak: 2},
+			expectedError: "container container_id failed 2 consecutive health checks",
+		},
+	}
+	for _, c := range cases {
+		daemon := newWaitDaemon(c.state)
+		daemon.noEvents = c.update == nil
+		server, client := newTestServerClient(t, daemon.ServeHTTP)
+
+		c.options.Condition = types.WaitConditionHealthy
+		results, errs := waitFor(t, client, daemon, c.options)
+		if c.update != nil {
+			daemon.update("health_status", c.update)
+		}
+		err := <-errs
+		server.Close()
+		if err == nil || err.Error() != c.expectedError {
+			t.Fatalf("expected %q, got %v", c.expectedError, err)
+		}
+		if result := <-results; result.State == nil {
+			t.Fatalf("expected the state of the container, got %+v", result)
+		}
+	}
+}
+
+func TestContainerWaitConditionRemovedWithoutEvents(t *testing.T) {
+	daemon := newWaitDaemon(types.ContainerState{Status: "exited"})
+	daemon.noEvents = true
+	server, client := newTestServerClient(t, daemon.ServeHTTP)
+	defer server.Close()
+
+	results, errs := waitFor(t, client, daemon, types.ContainerWaitOptions{
+		Condition:    types.WaitConditionRemoved,
+		PollInterval: 10 * time.Millisecond,
+	})
+	daemon.mu.Lock()
+	daemon.state = nil
+	daemon.mu.Unlock()
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if result := <-results; result.State != nil {
+		t.Fatalf("expected no state for a removed container, got %+v", result)
+	}
+}
+
+func TestContainerWaitConditionErrors(t *testing.T) {
+	daemon := newWaitDaemon(types.ContainerState{Running: true})
+	server, client := newTestServerClient(t, daemon.ServeHTTP)
+	defer server.Close()
+
+	_, err := client.ContainerWaitCondition(context.Background(), "container_id", types.ContainerWaitOptions{Condition: "paused"})
+	if err == nil || err.Error() != "invalid wait condition `paused`" {
+		t.Fatalf("expected an invalid condition error, got %v", err)
+	}
+
+	_, err = client.ContainerWaitCondition(context.Background(), "unknown", types.ContainerWaitOptions{})
+	if !IsErrContainerNotFound(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.ContainerWaitCondition(ctx, "container_id", types.ContainerWaitOptions{})
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected the deadline to be exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package client
+
+import (
+	"encoding/json"
+
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
+	"github.com/docker/engine-api/types/versions"
+	"golang.org/x/net/context"
+)
+
+// diskUsageAPIVersion is the first version of the API with the disk usage endpoint.
+const diskUsageAPIVersion = "1.25"
+
+// DiskUsage returns the disk space used by the images, the containers,
+// the volumes and the build cache of the docker host.
+//
+// The disk usage is approximated from the lists of images and containers, and
+// the inspection of the volumes, when the daemon doesn't support the endpoint.
+// The images only share the layers of their parents then, and the size of
+// the volumes and of the build cache isn't available.
+func (cli *Client) DiskUsage(ctx context.Context) (types.DiskUsage, error) {
+	if err := cli.negotiateAPIVersionOnce(ctx); err != nil {
+		return types.DiskUsage{}, err
+	}
+	if version := cli.ClientVersion(); version != "" && versions.LessThan(version, diskUsageAPIVersion) {
+		return cli.computeDiskUsage(ctx)
+	}
+
+	resp, err := cli.get(ctx, "/system/df", nil, nil)
+	if err != nil {
+		if IsErrNotFound(err) {
+			// The daemon predates the endpoint.
+			return cli.computeDiskUsage(ctx)
+		}
+		return types.DiskUsage{}, err
+	}
+
+	var du types.DiskUsage
+	err = json.NewDecoder(resp.body).Decode(&du)
+	ensureReaderClosed(resp)
+	return du, err
+}
+
+// computeDiskUsage approximates the disk usage on the client side.
+func (cli *Client) computeDiskUsage(ctx context.Context) (types.DiskUsage, error) {
+	var du types.DiskUsage
+
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{All: true, Size: true})
+	if err != nil {
+		return du, err
+	}
+	imageContainers := make(map[string]int64)
+	volumeRefs := make(map[string]int64)
+	for i := range containers {
+		c := &containers[i]
+		imageContainers[c.ImageID]++
+		for _, m := range c.Mounts {
+			if m.Name != "" {
+				volumeRefs[m.Name]++
+			}
+		}
+		du.Containers = append(du.Containers, c)
+	}
+
+	images, err := cli.ImageList(ctx, types.ImageListOptions{})
+	if err != nil {
+		return du, err
+	}
+	// The intermediate images are listed too, for the layers
+	// the images share through their parents.
+	allImages, err := cli.ImageList(ctx, types.ImageListOptions{All: true})
+	if err != nil {
+		return du, err
+	}
+	layers := newImageLayers(allImages)
+	for _, img := range images {
+		layers.use(img.ID)
+	}
+	du.LayersSize = layers.size()
+	for i := range images {
+		img := &images[i]
+		img.Size = imageSize(*img)
+		img.SharedSize = layers.sharedSize(img.ID)
+		img.Containers = imageContainers[img.ID]
+		du.Images = append(du.Images, img)
+	}
+
+	volumes, err := cli.VolumeList(ctx, filters.NewArgs())
+	if err != nil {
+		return du, err
+	}
+	for _, v := range volumes.Volumes {
+		volume, err := cli.VolumeInspect(ctx, v.Name)
+		if err != nil {
+			if IsErrVolumeNotFound(err) {
+				// The volume was removed since it was listed.
+				continue
+			}
+			return du, err
+		}
+		volume.UsageData = &types.VolumeUsageData{
+			Size:     -1,
+			RefCount: volumeRefs[volume.Name],
+		}
+		du.Volumes = append(du.Volumes, &volume)
+	}
+	return du, nil
+}
+
+// imageSize returns the size of the image, including its parents.
+// Daemons prior to API 1.22 only report it as the virtual size.
+func imageSize(img types.Image) int64 {
+	if img.VirtualSize > img.Size {
+		return img.VirtualSize
+	}
+	return img.Size
+}
+
+// imageLayers is the tree of the images, each image being the layer
+// it adds to its parent, and counts the images using each layer.
+type imageLayers struct {
+	parents map[string]string
+	sizes   map[string]int64
+	refs    map[string]int
+}
+
+func newImageLayers(images []types.Image) *imageLayers {
+	l := &imageLayers{
+		parents: make(map[string]string),
+		sizes:   make(map[string]int64),
+		refs:    make(map[string]int),
+	}
+	for _, img := range images {
+		l.parents[img.ID] = img.ParentID
+		l.sizes[img.ID] = imageSize(img)
+	}
+	return l
+}
+
+// walk calls the function with the image and its parents,
+// from the image to the base image, until it returns false.
+func (l *imageLayers) walk(imageID string, fn func(imageID string) bool) {
+	seen := make(map[string]bool)
+	for id := imageID; id != "" && !seen[id]; id = l.parents[id] {
+		seen[id] = true
+		if !fn(id) {
+			return
+		}
+	}
+}
+
+// use counts the image as using its layers and the layers of its parents.
+func (l *imageLayers) use(imageID string) {
+	l.walk(imageID, func(id string) bool {
+		l.refs[id]++
+		return true
+	})
+}
+
+// sharedSize returns the size of the layers of the image used by
+// other images, which is the size of its closest shared parent.
+func (l *imageLayers) sharedSize(imageID string) int64 {
+	var shared int64
+	l.walk(imageID, func(id string) bool {
+		if l.refs[id] > 1 {
+			shared = l.sizes[id]
+			return false
+		}
+		return true
+	})
+	return shared
+}
+
+// size returns the total size of the layers in use.
+func (l *imageLayers) size() int64 {
+	var total int64
+	for id, refs := range l.refs {
+		if refs == 0 {
+			continue
+		}
+		size := l.sizes[id]
+		if parentSize, ok := l.sizes[l.parents[id]]; ok && parentSize <= size {
+			size -= parentSize
+		}
+		total += size
+	}
+	return total
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+)
+
+func TestDiskUsageError(t *testing.T) {
+	client := &Client{
+		transport: newMockClient(nil, errorMock(http.StatusInternalServerError, "Server error")),
+		version:   "1.25",
+	}
+
+	_, err := client.DiskUsage(context.Background())
+	if err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+}
+
+func TestDiskUsage(t *testing.T) {
+	var requests []string
+	client := &Client{
+		transport: newMockClient(nil, pruneMock(map[string]interface{}{
+			"GET /v1.25/system/df": types.DiskUsage{
+				LayersSize: 1024,
+				Images:     []*types.Image{{ID: "image_id", Size: 1024, SharedSize: 512, Containers: 1}},
+				Containers: []*types.Container{{ID: "container_id", SizeRw: 10, SizeRootFs: 1034}},
+				Volumes: []*types.Volume{
+					{Name: "volume", UsageData: &types.VolumeUsageData{Size: 2048, RefCount: 1}},
+				},
+			},
+		}, &requests)),
+		version: "1.25",
+	}
+
+	du, err := client.DiskUsage(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if du.LayersSize != 1024 || len(du.Images) != 1 || du.Images[0].UniqueSize() != 512 {
+		t.Fatalf("unexpected images usage %+v", du)
+	}
+	if len(du.Containers) != 1 || du.Containers[0].SizeRootFs != 1034 {
+		t.Fatalf("unexpected containers usage %+v", du.Containers)
+	}
+	if len(du.Volumes) != 1 || *du.Volumes[0].UsageData != (types.VolumeUsageData{Size: 2048, RefCount: 1}) {
+		t.Fatalf("unexpected volumes usage %+v", du.Volumes)
+	}
+}
+
+func TestDiskUsageFallback(t *testing.T) {
+	containers := []types.Container{
+		{ID: "container1", ImageID: "app1", SizeRw: 10, Mounts: []types.MountPoint{{Name: "data"}}},
+		{ID: "container2", ImageID: "app1", SizeRw: 20, Mounts: []types.MountPoint{{Name: "data"}, {Source: "/host"}}},
+		{ID: "container3", ImageID: "solo", SizeRw: 40},
+	}
+	images := []types.Image{
+		{ID: "base", RepoTags: []string{"base:latest"}, Size: 100},
+		{ID: "app1", ParentID: "base", RepoTags: []string{"app1:latest"}, Size: 150},
+		{ID: "app2", ParentID: "base", RepoTags: []string{"app2:latest"}, Size: 180},
+		{ID: "solo", RepoTags: []string{"solo:latest"}, Size: 50},
+	}
+	allImages := append([]types.Image{{ID: "unused", Size: 1000}}, images...)
+
+	var requests []string
+	mock := pruneMock(map[string]interface{}{
+		"GET /v1.24/containers/json": containers,
+		"GET /v1.24/volumes": types.VolumesListResponse{
+			Volumes: []*types.Volume{{Name: "data"}, {Name: "unused"}, {Name: "removed"}},
+		},
+		"GET /v1.24/volumes/data":    types.Volume{Name: "data", Driver: "local"},
+		"GET /v1.24/volumes/unused":  types.Volume{Name: "unused", Driver: "local"},
+		"GET /v1.24/volumes/removed": http.StatusNotFound,
+	}, &requests)
+	client := &Client{
+		transport: newMockClient(nil, func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/v1.24/images/json" {
+				if req.URL.Query().Get("all") == "1" {
+					return pruneMock(map[string]interface{}{"GET /v1.24/images/json": allImages}, &requests)(req)
+				}
+				return pruneMock(map[string]interface{}{"GET /v1.24/images/json": images}, &requests)(req)
+			}
+			return mock(req)
+		}),
+		version: "1.24",
+	}
+
+	du, err := client.DiskUsage(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if du.LayersSize != 280 {
+		t.Fatalf("expected 280 bytes of layers, got %d", du.LayersSize)
+	}
+	expectedImages := map[string][2]int64{
+		"base": {100, 0},
+		"app1": {100, 2},
+		"app2": {100, 0},
+		"solo": {0, 1},
+	}
+	imagesUsage := make(map[string][2]int64)
+	for _, img := range du.Images {
+		imagesUsage[img.ID] = [2]int64{img.SharedSize, img.Containers}
+	}
+	if !reflect.DeepEqual(imagesUsage, expectedImages) {
+		t.Fatalf("expected shared sizes and containers %v, got %v", expectedImages, imagesUsage)
+	}
+	if len(du.Containers) != 3 || du.Containers[2].SizeRw != 40 {
+		t.Fatalf("unexpected containers usage %+v", du.Containers)
+	}
+	volumes := make(map[string]types.VolumeUsageData)
+	for _, v := range du.Volumes {
+		volumes[v.Name] = *v.UsageData
+	}
+	expectedVolumes := map[string]types.VolumeUsageData{
+		"data":   {Size: -1, RefCount: 2},
+		"unused": {Size: -1, RefCount: 0},
+	}
+	if !reflect.DeepEqual(volumes, expectedVolumes) {
+		t.Fatalf("expected volumes usage %v, got %v", expectedVolumes, volumes)
+	}
+}
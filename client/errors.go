@@ -3,6 +3,9 @@ package client
 import (
 	"errors"
 	"fmt"
+	"net/http"
+
+	"github.com/docker/engine-api/types"
 )
 
 // ErrConnectionFailed is an error raised when the connection between the client and the server failed.
@@ -10,7 +13,124 @@ var ErrConnectionFailed = errors.New("Cannot connect to the Docker daemon. Is th
 
 // ErrorConnectionFailed returns an error with host in the error message when connection to docker daemon failed.
 func ErrorConnectionFailed(host string) error {
-	return fmt.Errorf("Cannot connect to the Docker daemon at %s. Is the docker daemon running?", host)
+	return connectionFailedError{host}
+}
+
+// connectionFailedError implements an error returned when the client can't connect to the docker host.
+type connectionFailedError struct {
+	host string
+}
+
+// Error returns a string representation of a connectionFailedError
+func (e connectionFailedError) Error() string {
+	return fmt.Sprintf("Cannot connect to the Docker daemon at %s. Is the docker daemon running?", e.host)
+}
+
+// IsErrConnectionFailed returns true if the error is caused
+// when the connection to the docker host failed.
+func IsErrConnectionFailed(err error) bool {
+	return matchError(err, func(err error) bool {
+		_, ok := err.(connectionFailedError)
+		return ok || err == ErrConnectionFailed
+	})
+}
+
+// causer is implemented by the errors that wrap another error.
+type causer interface {
+	Cause() error
+}
+
+// matchError returns true if the error, or any of the errors
+// it wraps, satisfies the given function.
+func matchError(err error, match func(error) bool) bool {
+	for err != nil {
+		if match(err) {
+			return true
+		}
+		c, ok := err.(causer)
+		if !ok {
+			return false
+		}
+		err = c.Cause()
+	}
+	return false
+}
+
+// ServerError is the error returned when the docker host
+// replies to a request with an error status code.
+type ServerError struct {
+	// StatusCode is the http status code of the response.
+	StatusCode int
+	// Method is the http method of the request.
+	Method string
+	// Path is the path of the request, including the version of the API.
+	Path string
+	// Version is the version of the API the request was sent with.
+	Version string
+	// Response is the decoded body of the response.
+	Response types.ErrorResponse
+}
+
+// Error returns a string representation of a ServerError
+func (e ServerError) Error() string {
+	if e.Response.Message == "" {
+		return fmt.Sprintf("Error: request returned %s for API route and version %s, check if the server supports the requested API version", http.StatusText(e.StatusCode), e.Path)
+	}
+	return "Error response from daemon: " + e.Response.Message
+}
+
+// AsServerError returns the ServerError the error is, or wraps.
+func AsServerError(err error) (ServerError, bool) {
+	var serverErr ServerError
+	ok := matchError(err, func(err error) bool {
+		e, ok := err.(ServerError)
+		if ok {
+			serverErr = e
+		}
+		return ok
+	})
+	return serverErr, ok
+}
+
+// isStatusCode returns true if the error is, or wraps,
+// a ServerError with the given status code.
+func isStatusCode(err error, statusCode int) bool {
+	serverErr, ok := AsServerError(err)
+	return ok && serverErr.StatusCode == statusCode
+}
+
+// IsErrBadRequest returns true if the error is caused
+// when the docker host rejects an invalid request.
+func IsErrBadRequest(err error) bool {
+	return isStatusCode(err, http.StatusBadRequest)
+}
+
+// IsErrForbidden returns true if the error is caused
+// when the docker host forbids the operation.
+func IsErrForbidden(err error) bool {
+	return isStatusCode(err, http.StatusForbidden)
+}
+
+// IsErrConflict returns true if the error is caused
+// when the operation conflicts with the state of an object
+// in the docker host, like removing a running container.
+func IsErrConflict(err error) bool {
+	return isStatusCode(err, http.StatusConflict)
+}
+
+// IsErrNotModified returns true if the error is caused
+// when the operation doesn't change an object in the docker host.
+// Note that the requests the docker host answers with 304 Not Modified,
+// like starting a running container, don't fail.
+func IsErrNotModified(err error) bool {
+	return isStatusCode(err, http.StatusNotModified)
+}
+
+// IsErrUnavailable returns true if the error is caused
+// when the docker host can't serve the request, like
+// a swarm request to a node that is not a manager.
+func IsErrUnavailable(err error) bool {
+	return isStatusCode(err, http.StatusServiceUnavailable)
 }
 
 type notFound interface {
@@ -21,13 +141,21 @@ type notFound interface {
 // IsErrNotFound returns true if the error is caused with an
 // object (image, container, network, volume, …) is not found in the docker host.
 func IsErrNotFound(err error) bool {
-	te, ok := err.(notFound)
-	return ok && te.NotFound()
+	return isStatusCode(err, http.StatusNotFound) || matchError(err, func(err error) bool {
+		te, ok := err.(notFound)
+		return ok && te.NotFound()
+	})
 }
 
 // imageNotFoundError implements an error returned when an image is not in the docker host.
 type imageNotFoundError struct {
 	imageID string
+	cause   error
+}
+
+// Cause returns the error returned by the docker host
+func (e imageNotFoundError) Cause() error {
+	return e.cause
 }
 
 // NoFound indicates that this error type is of NotFound
@@ -49,6 +177,12 @@ func IsErrImageNotFound(err error) bool {
 // containerNotFoundError implements an error returned when a container is not in the docker host.
 type containerNotFoundError struct {
 	containerID string
+	cause       error
+}
+
+// Cause returns the error returned by the docker host
+func (e containerNotFoundError) Cause() error {
+	return e.cause
 }
 
 // NoFound indicates that this error type is of NotFound
@@ -70,6 +204,12 @@ func IsErrContainerNotFound(err error) bool {
 // networkNotFoundError implements an error returned when a network is not in the docker host.
 type networkNotFoundError struct {
 	networkID string
+	cause     error
+}
+
+// Cause returns the error returned by the docker host
+func (e networkNotFoundError) Cause() error {
+	return e.cause
 }
 
 // NoFound indicates that this error type is of NotFound
@@ -91,6 +231,12 @@ func IsErrNetworkNotFound(err error) bool {
 // volumeNotFoundError implements an error returned when a volume is not in the docker host.
 type volumeNotFoundError struct {
 	volumeID string
+	cause    error
+}
+
+// Cause returns the error returned by the docker host
+func (e volumeNotFoundError) Cause() error {
+	return e.cause
 }
 
 // NoFound indicates that this error type is of NotFound
@@ -119,16 +265,30 @@ func (u unauthorizedError) Error() string {
 	return u.cause.Error()
 }
 
+// Cause returns the error returned by the docker host
+func (u unauthorizedError) Cause() error {
+	return u.cause
+}
+
 // IsErrUnauthorized returns true if the error is caused
-// when a remote registry authentication fails
+// when a remote registry authentication fails, or when
+// the docker host rejects the credentials of a request.
 func IsErrUnauthorized(err error) bool {
-	_, ok := err.(unauthorizedError)
-	return ok
+	return isStatusCode(err, http.StatusUnauthorized) || matchError(err, func(err error) bool {
+		_, ok := err.(unauthorizedError)
+		return ok
+	})
 }
 
 // nodeNotFoundError implements an error returned when a node is not found.
 type nodeNotFoundError struct {
 	nodeID string
+	cause  error
+}
+
+// Cause returns the error returned by the docker host
+func (e nodeNotFoundError) Cause() error {
+	return e.cause
 }
 
 // Error returns a string representation of a nodeNotFoundError
@@ -144,13 +304,51 @@ func (e nodeNotFoundError) NotFound() bool {
 // IsErrNodeNotFound returns true if the error is caused
 // when a node is not found.
 func IsErrNodeNotFound(err error) bool {
-	_, ok := err.(nodeNotFoundError)
-	return ok
+	return matchError(err, func(err error) bool {
+		_, ok := err.(nodeNotFoundError)
+		return ok
+	})
+}
+
+// secretNotFoundError implements an error returned when a secret is not found.
+type secretNotFoundError struct {
+	secretID string
+	cause    error
+}
+
+// Cause returns the error returned by the docker host
+func (e secretNotFoundError) Cause() error {
+	return e.cause
+}
+
+// Error returns a string representation of a secretNotFoundError
+func (e secretNotFoundError) Error() string {
+	return fmt.Sprintf("Error: No such secret: %s", e.secretID)
+}
+
+// NoFound indicates that this error type is of NotFound
+func (e secretNotFoundError) NotFound() bool {
+	return true
+}
+
+// IsErrSecretNotFound returns true if the error is caused
+// when a secret is not found.
+func IsErrSecretNotFound(err error) bool {
+	return matchError(err, func(err error) bool {
+		_, ok := err.(secretNotFoundError)
+		return ok
+	})
 }
 
 // serviceNotFoundError implements an error returned when a service is not found.
 type serviceNotFoundError struct {
 	serviceID string
+	cause     error
+}
+
+// Cause returns the error returned by the docker host
+func (e serviceNotFoundError) Cause() error {
+	return e.cause
 }
 
 // Error returns a string representation of a serviceNotFoundError
@@ -166,13 +364,21 @@ func (e serviceNotFoundError) NotFound() bool {
 // IsErrServiceNotFound returns true if the error is caused
 // when a service is not found.
 func IsErrServiceNotFound(err error) bool {
-	_, ok := err.(serviceNotFoundError)
-	return ok
+	return matchError(err, func(err error) bool {
+		_, ok := err.(serviceNotFoundError)
+		return ok
+	})
 }
 
 // taskNotFoundError implements an error returned when a task is not found.
 type taskNotFoundError struct {
 	taskID string
+	cause  error
+}
+
+// Cause returns the error returned by the docker host
+func (e taskNotFoundError) Cause() error {
+	return e.cause
 }
 
 // Error returns a string representation of a taskNotFoundError
@@ -188,8 +394,10 @@ func (e taskNotFoundError) NotFound() bool {
 // IsErrTaskNotFound returns true if the error is caused
 // when a task is not found.
 func IsErrTaskNotFound(err error) bool {
-	_, ok := err.(taskNotFoundError)
-	return ok
+	return matchError(err, func(err error) bool {
+		_, ok := err.(taskNotFoundError)
+		return ok
+	})
 }
 
 type pluginPermissionDenied struct {
@@ -203,6 +411,8 @@ func (e pluginPermissionDenied) Error() string {
 // IsErrPluginPermissionDenied returns true if the error is caused
 // when a user denies a plugin's permissions
 func IsErrPluginPermissionDenied(err error) bool {
-	_, ok := err.(pluginPermissionDenied)
-	return ok
+	return matchError(err, func(err error) bool {
+		_, ok := err.(pluginPermissionDenied)
+		return ok
+	})
 }
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// wrappedError wraps another error the way github.com/pkg/errors does.
+type wrappedError struct {
+	cause error
+}
+
+func (e wrappedError) Error() string {
+	return "wrapped: " + e.cause.Error()
+}
+
+func (e wrappedError) Cause() error {
+	return e.cause
+}
+
+func TestErrorPredicates(t *testing.T) {
+	cases := []struct {
+		statusCode int
+		predicate  func(error) bool
+	}{
+		{statusCode: http.StatusNotModified, predicate: IsErrNotModified},
+		{statusCode: http.StatusBadRequest, predicate: IsErrBadRequest},
+		{statusCode: http.StatusUnauthorized, predicate: IsErrUnauthorized},
+		{statusCode: http.StatusForbidden, predicate: IsErrForbidden},
+		{statusCode: http.StatusNotFound, predicate: IsErrNotFound},
+		{statusCode: http.StatusConflict, predicate: IsErrConflict},
+		{statusCode: http.StatusServiceUnavailable, predicate: IsErrUnavailable},
+	}
+	for _, c := range cases {
+		err := ServerError{StatusCode: c.statusCode}
+		if !c.predicate(err) || !c.predicate(wrappedError{wrappedError{err}}) {
+			t.Fatalf("expected the predicate to match a %d error", c.statusCode)
+		}
+		if c.predicate(ServerError{StatusCode: http.StatusInternalServerError}) || c.predicate(fmt.Errorf("%v", err)) {
+			t.Fatalf("expected the predicate to only match %d errors", c.statusCode)
+		}
+	}
+}
+
+func TestNotFoundErrorsWrapServerError(t *testing.T) {
+	serverErr := ServerError{StatusCode: http.StatusNotFound}
+	err := wrappedError{nodeNotFoundError{"node_id", serverErr}}
+	if !IsErrNodeNotFound(err) || !IsErrNotFound(err) || IsErrServiceNotFound(err) {
+		t.Fatalf("expected a node not found error, got %v", err)
+	}
+	if _, ok := AsServerError(err); !ok {
+		t.Fatalf("expected the not found error to wrap the ServerError")
+	}
+	if !IsErrUnauthorized(wrappedError{unauthorizedError{fmt.Errorf("unauthorized")}}) {
+		t.Fatalf("expected an unauthorized error through wrapping")
+	}
+}
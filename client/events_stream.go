@@ -0,0 +1,206 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/events"
+	timetypes "github.com/docker/engine-api/types/time"
+)
+
+// defaultEventsBufferSize is the number of events buffered when
+// the options don't set a size.
+const defaultEventsBufferSize = 64
+
+// EventsStream returns the events in the daemon decoded as they arrive.
+// The messages channel is closed when the stream ends, either because
+// it reached options.Until, the context is done or an error happened.
+// The error, if any, is sent to the errors channel before it's closed.
+//
+// When the connection is dropped, the request is issued again with
+// Since set to the time of the last event received, so events are
+// neither lost nor delivered twice.
+func (cli *Client) EventsStream(ctx context.Context, options types.EventsStreamOptions) (<-chan events.Message, <-chan error) {
+	size := options.BufferSize
+	if size <= 0 {
+		size = defaultEventsBufferSize
+	}
+	messages := make(chan events.Message, size)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(messages)
+		if err := cli.streamEvents(ctx, options, messages); err != nil {
+			errs <- err
+		}
+	}()
+
+	return messages, errs
+}
+
+func (cli *Client) streamEvents(ctx context.Context, options types.EventsStreamOptions, messages chan events.Message) error {
+	// Resolve relative times once, reconnections must not move the window.
+	ref := time.Now()
+	if options.Since != "" {
+		ts, err := timetypes.GetTimestamp(options.Since, ref)
+		if err != nil {
+			return err
+		}
+		options.Since = ts
+	}
+	if options.Until != "" {
+		ts, err := timetypes.GetTimestamp(options.Until, ref)
+		if err != nil {
+			return err
+		}
+		options.Until = ts
+	}
+
+	var (
+		cursor     eventsCursor
+		reconnects int
+	)
+	for {
+		body, err := cli.Events(ctx, options.EventsOptions)
+		if err == nil {
+			delivered := cursor.delivered
+			err = decodeEvents(ctx, body, &cursor, options.Overflow, messages)
+			body.Close()
+			if err == nil && options.Until != "" {
+				return nil
+			}
+			if cursor.delivered > delivered {
+				reconnects = 0
+			}
+		} else if cursor.delivered == 0 && reconnects == 0 {
+			// Don't retry requests the daemon never accepted.
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if options.MaxReconnects < 0 || (options.MaxReconnects > 0 && reconnects >= options.MaxReconnects) {
+			if err == nil {
+				err = io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		reconnects++
+
+		if since := cursor.since(); since != "" {
+			options.Since = since
+		}
+
+		if options.ReconnectDelay > 0 {
+			select {
+			case <-time.After(options.ReconnectDelay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// decodeEvents reads events from the body until it's exhausted,
+// sending the ones that the cursor has not seen yet.
+func decodeEvents(ctx context.Context, body io.Reader, cursor *eventsCursor, policy types.EventsOverflowPolicy, messages chan events.Message) error {
+	dec := json.NewDecoder(body)
+	for {
+		var m events.Message
+		if err := dec.Decode(&m); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if !cursor.advance(m) {
+			continue
+		}
+		if err := sendEvent(ctx, m, policy, messages); err != nil {
+			return err
+		}
+	}
+}
+
+// sendEvent queues an event in the messages channel applying the overflow policy.
+func sendEvent(ctx context.Context, m events.Message, policy types.EventsOverflowPolicy, messages chan events.Message) error {
+	switch policy {
+	case types.EventsOverflowDropNewest:
+		select {
+		case messages <- m:
+		default:
+		}
+		return nil
+	case types.EventsOverflowDropOldest:
+		for {
+			select {
+			case messages <- m:
+				return nil
+			default:
+			}
+			select {
+			case <-messages:
+			default:
+			}
+		}
+	default:
+		select {
+		case messages <- m:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// eventsCursor keeps track of the last events delivered.
+// The daemon includes events that happened exactly at the Since time,
+// so the cursor remembers all the events received at that time to
+// discard them when the stream is resumed.
+type eventsCursor struct {
+	timeNano  int64
+	seen      []events.Message
+	delivered int
+}
+
+// advance records the event and returns whether it had not been seen before.
+func (c *eventsCursor) advance(m events.Message) bool {
+	t := m.TimeNano
+	if t == 0 {
+		t = m.Time * int64(time.Second)
+	}
+
+	switch {
+	case t < c.timeNano:
+		return false
+	case t == c.timeNano:
+		for _, s := range c.seen {
+			if reflect.DeepEqual(s, m) {
+				return false
+			}
+		}
+		c.seen = append(c.seen, m)
+	default:
+		c.timeNano = t
+		c.seen = []events.Message{m}
+	}
+	c.delivered++
+	return true
+}
+
+// since returns the timestamp to resume the stream from,
+// or an empty string if no events have been received.
+func (c *eventsCursor) since() string {
+	if c.delivered == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d.%09d", c.timeNano/int64(time.Second), c.timeNano%int64(time.Second))
+}
@@ -0,0 +1,234 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/events"
+	"github.com/docker/engine-api/types/filters"
+)
+
+func newTestServerClient(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *Client) {
+	server := httptest.NewServer(handler)
+	client, err := NewClient("tcp://"+strings.TrimPrefix(server.URL, "http://"), "1.24", nil, nil)
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	return server, client
+}
+
+func testEvent(action, id string, timeNano int64) events.Message {
+	return events.Message{
+		Type:     events.ContainerEventType,
+		Action:   action,
+		Actor:    events.Actor{ID: id, Attributes: map[string]string{"image": "busybox", "exitCode": "3"}},
+		Time:     timeNano / 1e9,
+		TimeNano: timeNano,
+	}
+}
+
+func writeEvents(w io.Writer, msgs ...events.Message) {
+	enc := json.NewEncoder(w)
+	for _, m := range msgs {
+		enc.Encode(m)
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func collectEvents(messages <-chan events.Message, errs <-chan error) ([]events.Message, error) {
+	var received []events.Message
+	for m := range messages {
+		received = append(received, m)
+	}
+	return received, <-errs
+}
+
+func TestEventsStreamErrorFromServer(t *testing.T) {
+	server, client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Server error", http.StatusInternalServerError)
+	})
+	defer server.Close()
+
+	_, err := collectEvents(client.EventsStream(context.Background(), types.EventsStreamOptions{}))
+	if err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+}
+
+func TestEventsStreamFilters(t *testing.T) {
+	server, client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if actual := r.URL.Query().Get("filters"); actual != `{"container":{"foo":true}}` {
+			http.Error(w, "unexpected filters "+actual, http.StatusBadRequest)
+			return
+		}
+		writeEvents(w, testEvent("die", "foo", 1000000000000000001))
+	})
+	defer server.Close()
+
+	options := types.EventsStreamOptions{}
+	options.Filters = filters.NewArgs()
+	options.Filters.Add("container", "foo")
+	options.Until = "1000000001"
+
+	received, err := collectEvents(client.EventsStream(context.Background(), options))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(received))
+	}
+	if code, ok := received[0].Actor.ExitCode(); !ok || code != 3 {
+		t.Fatalf("expected exit code 3, got %d", code)
+	}
+	if image := received[0].Actor.Image(); image != "busybox" {
+		t.Fatalf("expected image busybox, got %s", image)
+	}
+}
+
+func TestEventsStreamReconnects(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	server, client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.Query().Get("since"))
+		n := len(requests)
+		mu.Unlock()
+
+		if n == 1 {
+			// Drop the connection in the middle of the stream.
+			conn, buf, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			defer conn.Close()
+			fmt.Fprint(buf, "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 4096\r\n\r\n")
+			writeEvents(buf, testEvent("create", "1", 1000000000000000001), testEvent("start", "1", 1000000000000000002))
+			buf.Flush()
+			return
+		}
+		writeEvents(w, testEvent("start", "1", 1000000000000000002), testEvent("die", "1", 1000000000000000003))
+	})
+	defer server.Close()
+
+	options := types.EventsStreamOptions{}
+	options.Until = "1000000001"
+	received, err := collectEvents(client.EventsStream(context.Background(), options))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var actions []string
+	for _, m := range received {
+		actions = append(actions, m.Action)
+	}
+	if strings.Join(actions, ",") != "create,start,die" {
+		t.Fatalf("expected create,start,die events, got %v", actions)
+	}
+	if len(requests) != 2 || requests[1] != "1000000000.000000002" {
+		t.Fatalf("expected to resume from the last event, got %v", requests)
+	}
+}
+
+func TestEventsStreamMaxReconnects(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	server, client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		writeEvents(w, testEvent("create", "1", 1000000000000000001))
+	})
+	defer server.Close()
+
+	options := types.EventsStreamOptions{MaxReconnects: 2}
+	received, err := collectEvents(client.EventsStream(context.Background(), options))
+	if err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected an unexpected EOF error, got %v", err)
+	}
+	if len(received) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(received))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 3 {
+		t.Fatalf("expected 3 requests, got %d", requests)
+	}
+}
+
+func TestEventsStreamCancel(t *testing.T) {
+	done := make(chan struct{})
+	server, client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w, testEvent("create", "1", 1000000000000000001))
+		<-done
+	})
+	defer server.Close()
+	defer close(done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	messages, errs := client.EventsStream(ctx, types.EventsStreamOptions{})
+	if m := <-messages; m.Action != "create" {
+		t.Fatalf("expected a create event, got %v", m)
+	}
+	cancel()
+
+	if _, err := collectEvents(messages, errs); err != context.Canceled {
+		t.Fatalf("expected context canceled, got %v", err)
+	}
+}
+
+func TestEventsStreamOverflow(t *testing.T) {
+	cases := []struct {
+		policy   types.EventsOverflowPolicy
+		expected string
+	}{
+		{
+			policy:   types.EventsOverflowDropNewest,
+			expected: "create",
+		},
+		{
+			policy:   types.EventsOverflowDropOldest,
+			expected: "die",
+		},
+	}
+	for _, c := range cases {
+		server, client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+			writeEvents(w,
+				testEvent("create", "1", 1000000000000000001),
+				testEvent("start", "1", 1000000000000000002),
+				testEvent("die", "1", 1000000000000000003))
+		})
+
+		options := types.EventsStreamOptions{BufferSize: 1, Overflow: c.policy}
+		options.Until = "1000000001"
+		messages, errs := client.EventsStream(context.Background(), options)
+		// Wait for the stream to end before consuming any event.
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+		received, _ := collectEvents(messages, errs)
+		server.Close()
+
+		if len(received) != 1 || received[0].Action != c.expected {
+			t.Fatalf("expected only the %s event, got %v", c.expected, received)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/engine-api/stdcopy"
+	"github.com/docker/engine-api/types"
+)
+
+// execPollInterval is the time to wait between the inspections
+// of an exec process whose output ended but is still running.
+var execPollInterval = 50 * time.Millisecond
+
+// ExecRun runs a command in a running container and waits for it to exit.
+// It returns the output of the command, split into stdout and stderr when
+// there is no TTY attached, its exit code and the time it took to run.
+func (cli *Client) ExecRun(ctx context.Context, container string, options types.ExecRunOptions) (types.ExecRunResult, error) {
+	config := types.ExecConfig{
+		User:         options.User,
+		Privileged:   options.Privileged,
+		Tty:          options.Tty,
+		AttachStdin:  options.Stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+		Env:          options.Env,
+		Cmd:          options.Cmd,
+	}
+	created, err := cli.ContainerExecCreate(ctx, container, config)
+	if err != nil {
+		return types.ExecRunResult{}, err
+	}
+
+	start := time.Now()
+	resp, err := cli.ContainerExecAttach(ctx, created.ID, config)
+	if err != nil {
+		return types.ExecRunResult{}, err
+	}
+	defer resp.Close()
+
+	if options.Stdin != nil {
+		go func() {
+			io.Copy(resp.Conn, options.Stdin)
+			resp.CloseWrite()
+		}()
+	}
+
+	var stdout, stderr bytes.Buffer
+	if _, err := stdcopy.Copy(&stdout, &stderr, resp.Reader, options.Tty); err != nil {
+		return types.ExecRunResult{}, err
+	}
+
+	// The output ends when the process exits,
+	// but the daemon may not have recorded its exit code yet.
+	for {
+		inspect, err := cli.ContainerExecInspect(ctx, created.ID)
+		if err != nil {
+			return types.ExecRunResult{}, err
+		}
+		if !inspect.Running {
+			return types.ExecRunResult{
+				Stdout:   stdout.Bytes(),
+				Stderr:   stderr.Bytes(),
+				ExitCode: inspect.ExitCode,
+				Duration: time.Since(start),
+			}, nil
+		}
+
+		select {
+		case <-time.After(execPollInterval):
+		case <-ctx.Done():
+			return types.ExecRunResult{}, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/engine-api/stdcopy"
+	"github.com/docker/engine-api/types"
+)
+
+func TestExecRun(t *testing.T) {
+	defer func(interval time.Duration) { execPollInterval = interval }(execPollInterval)
+	execPollInterval = time.Millisecond
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		server, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer server.Close()
+		br := bufio.NewReader(server)
+		req, err := http.ReadRequest(br)
+		if err != nil || req.URL.Path != "/exec/exec_id/start" {
+			return
+		}
+		ioutil.ReadAll(req.Body)
+		fmt.Fprint(server, "HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.raw-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+		input, err := ioutil.ReadAll(br)
+		if err != nil {
+			return
+		}
+		stdcopy.NewStdWriter(server, stdcopy.Stdout).Write(input)
+		fmt.Fprint(stdcopy.NewStdWriter(server, stdcopy.Stderr), "done")
+	}()
+
+	inspections := 0
+	client := &Client{
+		transport: dialerMock{
+			Client: newMockClient(nil, func(req *http.Request) (*http.Response, error) {
+				var body interface{}
+				switch req.URL.Path {
+				case "/containers/container_id/exec":
+					var config types.ExecConfig
+					if err := json.NewDecoder(req.Body).Decode(&config); err != nil {
+						return nil, err
+					}
+					if !config.AttachStdin || !config.AttachStdout || !config.AttachStderr || config.User != "nobody" || strings.Join(config.Cmd, " ") != "cat" {
+						return nil, fmt.Errorf("unexpected exec configuration %+v", config)
+					}
+					body = types.ContainerExecCreateResponse{ID: "exec_id"}
+				case "/exec/exec_id/json":
+					// The exec is still running the first time.
+					inspections++
+					body = types.ContainerExecInspect{ExecID: "exec_id", Running: inspections == 1, ExitCode: 2}
+				default:
+					return nil, fmt.Errorf("unexpected request to %s", req.URL.Path)
+				}
+				b, err := json.Marshal(body)
+				if err != nil {
+					return nil, err
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(bytes.NewReader(b)),
+				}, nil
+			}),
+			// The command reads its input until EOF, so the
+			// connection must support closing it for writing.
+			dial: func() (net.Conn, error) {
+				return net.Dial("tcp", l.Addr().String())
+			},
+		},
+	}
+
+	result, err := client.ExecRun(context.Background(), "container_id", types.ExecRunOptions{
+		User:  "nobody",
+		Cmd:   []string{"cat"},
+		Stdin: strings.NewReader("input"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(result.Stdout) != "input" || string(result.Stderr) != "done" {
+		t.Fatalf("expected the output to be split, got %q and %q", result.Stdout, result.Stderr)
+	}
+	if result.ExitCode != 2 || result.Duration <= 0 || inspections != 2 {
+		t.Fatalf("expected the exit code once the exec stopped running, got %+v after %d inspections", result, inspections)
+	}
+}
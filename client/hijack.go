@@ -1,13 +1,16 @@
 package client
 
 import (
+	"bufio"
 	"crypto/tls"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net"
-	"net/http/httputil"
+	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/engine-api/client/transport"
@@ -32,7 +35,14 @@ func (c *tlsClientCon) CloseWrite() error {
 }
 
 // postHijacked sends a POST request and hijacks the connection.
+// The context bounds the dial and the upgrade of the connection, and once
+// it's hijacked, cancelling the context closes it, making its reads and
+// writes fail with the error of the context.
 func (cli *Client) postHijacked(ctx context.Context, path string, query url.Values, body interface{}, headers map[string][]string) (types.HijackedResponse, error) {
+	if err := cli.negotiateAPIVersionOnce(ctx); err != nil {
+		return types.HijackedResponse{}, err
+	}
+
 	bodyEncoded, err := encodeData(body)
 	if err != nil {
 		return types.HijackedResponse{}, err
@@ -42,38 +52,130 @@ func (cli *Client) postHijacked(ctx context.Context, path string, query url.Valu
 	if err != nil {
 		return types.HijackedResponse{}, err
 	}
-	req.Host = cli.addr
+	req.Host = cli.urlHost()
+	req.URL.Host = cli.urlHost()
+	req.URL.Scheme = cli.getScheme()
 
 	req.Header.Set("Connection", "Upgrade")
 	req.Header.Set("Upgrade", "tcp")
 
-	conn, err := dial(cli.proto, cli.addr, cli.transport.TLSConfig())
+	conn, br, resp, err := cli.sendUpgrade(ctx, req)
 	if err != nil {
-		if strings.Contains(err.Error(), "connection refused") {
-			return types.HijackedResponse{}, fmt.Errorf("Cannot connect to the Docker daemon. Is 'docker daemon' running on this host?")
-		}
+		return types.HijackedResponse{}, err
+	}
+	if resp.StatusCode >= 400 {
+		err := cli.responseError(req, resp)
+		conn.Close()
 		return types.HijackedResponse{}, err
 	}
 
-	// When we set up a TCP connection for hijack, there could be long periods
-	// of inactivity (a long running command with no output) that in certain
-	// network setups may cause ECONNTIMEOUT, leaving the client in an unknown
-	// state. Setting TCP KeepAlive on the socket connection will prohibit
-	// ECONNTIMEOUT unless the socket connection truly is broken
-	if tcpConn, ok := conn.(*net.TCPConn); ok {
-		tcpConn.SetKeepAlive(true)
-		tcpConn.SetKeepAlivePeriod(30 * time.Second)
+	return types.HijackedResponse{Conn: conn, Reader: br}, nil
+}
+
+// sendUpgrade sends a request to upgrade the connection through the
+// middlewares of the client, on a connection of its own. It returns the
+// connection, the reader buffering it and the response of the docker host,
+// whose status code is up to the caller to check.
+func (cli *Client) sendUpgrade(ctx context.Context, req *http.Request) (net.Conn, *bufio.Reader, *http.Response, error) {
+	var (
+		conn net.Conn
+		br   *bufio.Reader
+	)
+	send := func(req *http.Request) (*http.Response, error) {
+		rawConn, err := cli.dial(ctx, req)
+		if err != nil {
+			if strings.Contains(err.Error(), "connection refused") {
+				return nil, fmt.Errorf("Cannot connect to the Docker daemon. Is 'docker daemon' running on this host?")
+			}
+			return nil, err
+		}
+		conn = newHijackedConn(ctx, rawConn)
+		br = bufio.NewReader(conn)
+
+		if err := req.Write(conn); err != nil {
+			return nil, err
+		}
+		return http.ReadResponse(br, req)
 	}
 
-	clientconn := httputil.NewClientConn(conn, nil)
-	defer clientconn.Close()
+	resp, err := cli.withMiddlewares(send)(req)
+	if err != nil {
+		if conn != nil {
+			conn.Close()
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, nil, nil, ctxErr
+		}
+		return nil, nil, nil, err
+	}
+	if conn == nil {
+		return nil, nil, nil, fmt.Errorf("unable to hijack the connection, the request wasn't sent to the docker host")
+	}
+	return conn, br, resp, nil
+}
 
-	// Server hijacks the connection, error 'connection closed' expected
-	_, err = clientconn.Do(req)
+// hijackedConn is a hijacked connection that is closed when its context is done.
+// Its reads and writes then fail with the error of the context.
+type hijackedConn struct {
+	net.Conn
+	ctx       context.Context
+	closed    chan struct{}
+	closeOnce sync.Once
+}
 
-	rwc, br := clientconn.Hijack()
+func newHijackedConn(ctx context.Context, conn net.Conn) *hijackedConn {
+	c := &hijackedConn{
+		Conn:   conn,
+		ctx:    ctx,
+		closed: make(chan struct{}),
+	}
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				c.Close()
+			case <-c.closed:
+			}
+		}()
+	}
+	return c
+}
+
+func (c *hijackedConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	return n, c.contextError(err)
+}
+
+func (c *hijackedConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	return n, c.contextError(err)
+}
+
+// contextError returns the error of the context instead of the error
+// of the connection if the context closed it.
+func (c *hijackedConn) contextError(err error) error {
+	if err != nil {
+		if ctxErr := c.ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+	}
+	return err
+}
+
+func (c *hijackedConn) Close() error {
+	err := errors.New("use of closed hijacked connection")
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		err = c.Conn.Close()
+	})
+	return err
+}
 
-	return types.HijackedResponse{Conn: rwc, Reader: br}, err
+func (c *hijackedConn) CloseWrite() error {
+	if conn, ok := c.Conn.(types.CloseWriter); ok {
+		return conn.CloseWrite()
+	}
+	return nil
 }
 
 func tlsDial(network, addr string, config *tls.Config) (net.Conn, error) {
@@ -116,16 +218,19 @@ func tlsDialWithDialer(dialer *net.Dialer, network, addr string, config *tls.Con
 	if err != nil {
 		return nil, err
 	}
-	// When we set up a TCP connection for hijack, there could be long periods
-	// of inactivity (a long running command with no output) that in certain
-	// network setups may cause ECONNTIMEOUT, leaving the client in an unknown
-	// state. Setting TCP KeepAlive on the socket connection will prohibit
-	// ECONNTIMEOUT unless the socket connection truly is broken
-	if tcpConn, ok := rawConn.(*net.TCPConn); ok {
-		tcpConn.SetKeepAlive(true)
-		tcpConn.SetKeepAlivePeriod(30 * time.Second)
+	setKeepAlive(rawConn)
+
+	conn, err := newTLSClientCon(rawConn, addr, config, errChannel)
+	if err != nil {
+		rawConn.Close()
+		return nil, err
 	}
+	return conn, nil
+}
 
+// newTLSClientCon runs the TLS handshake over the raw connection to the address.
+// If errChannel isn't nil, the handshake is aborted when it receives an error first.
+func newTLSClientCon(rawConn net.Conn, addr string, config *tls.Config, errChannel chan error) (net.Conn, error) {
 	colonPos := strings.LastIndex(addr, ":")
 	if colonPos == -1 {
 		colonPos = len(addr)
@@ -142,7 +247,8 @@ func tlsDialWithDialer(dialer *net.Dialer, network, addr string, config *tls.Con
 
 	conn := tls.Client(rawConn, config)
 
-	if timeout == 0 {
+	var err error
+	if errChannel == nil {
 		err = conn.Handshake()
 	} else {
 		go func() {
@@ -153,7 +259,6 @@ func tlsDialWithDialer(dialer *net.Dialer, network, addr string, config *tls.Con
 	}
 
 	if err != nil {
-		rawConn.Close()
 		return nil, err
 	}
 
@@ -162,7 +267,137 @@ func tlsDialWithDialer(dialer *net.Dialer, network, addr string, config *tls.Con
 	return &tlsClientCon{conn, rawConn}, nil
 }
 
-func dial(proto, addr string, tlsConfig *tls.Config) (net.Conn, error) {
+// dial opens a new connection to the docker server to hijack it, using the
+// transport of the client if it dials its own connections. Otherwise it uses
+// the dialer, the proxy and the tls configuration of the http client.
+func (cli *Client) dial(ctx context.Context, req *http.Request) (net.Conn, error) {
+	if dialer, ok := cli.transport.(transport.Dialer); ok {
+		return dialer.Dial()
+	}
+	tlsConfig := cli.transport.TLSConfig()
+	var tr *http.Transport
+	if cli.client != nil {
+		tr, _ = cli.client.Transport.(*http.Transport)
+	}
+	if tr == nil {
+		return dial(ctx, cli.proto, cli.addr, tlsConfig)
+	}
+	rawDial := transportDial(tr)
+	if rawDial == nil && cli.proto == "npipe" {
+		return dial(ctx, cli.proto, cli.addr, tlsConfig)
+	}
+	if rawDial == nil {
+		rawDial = defaultDial
+	}
+	if tr.TLSClientConfig != nil {
+		tlsConfig = tr.TLSClientConfig
+	}
+
+	proxyURL, err := cli.hijackProxy(tr, req)
+	if err != nil {
+		return nil, err
+	}
+	var rawConn net.Conn
+	if proxyURL != nil {
+		rawConn, err = dialProxy(ctx, rawDial, proxyURL, cli.addr)
+	} else {
+		rawConn, err = rawDial(ctx, cli.proto, cli.addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	setKeepAlive(rawConn)
+
+	if tlsConfig != nil && cli.proto != "unix" && cli.proto != "npipe" {
+		conn, err := newTLSClientCon(rawConn, cli.addr, tlsConfig, nil)
+		if err != nil {
+			rawConn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}
+	return rawConn, nil
+}
+
+// hijackProxy returns the url of the http proxy the transport
+// sends the request through, if any.
+func (cli *Client) hijackProxy(tr *http.Transport, req *http.Request) (*url.URL, error) {
+	if cli.proto != "tcp" || tr.Proxy == nil {
+		return nil, nil
+	}
+	proxyURL, err := tr.Proxy(req)
+	if err != nil || proxyURL == nil {
+		return nil, err
+	}
+	if proxyURL.Scheme != "http" {
+		return nil, fmt.Errorf("unable to hijack the connection through the proxy %s, unsupported scheme %s", proxyURL.Host, proxyURL.Scheme)
+	}
+	return proxyURL, nil
+}
+
+// dialProxy opens a tunnel to the address through an http proxy,
+// giving up when the context is done.
+func dialProxy(ctx context.Context, rawDial func(ctx context.Context, network, addr string) (net.Conn, error), proxyURL *url.URL, addr string) (net.Conn, error) {
+	conn, err := rawDial(ctx, "tcp", proxyURL.Host)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Done() != nil {
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-ctx.Done():
+				conn.Close()
+			case <-done:
+			}
+		}()
+	}
+	connectReq := &http.Request{
+		Method: "CONNECT",
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		connectReq.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := connectReq.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// The proxy doesn't send anything after its response
+	// until the tunnel is used, so nothing is buffered.
+	resp, err := http.ReadResponse(bufio.NewReader(conn), connectReq)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("unable to connect to %s through the proxy %s: %s", addr, proxyURL.Host, resp.Status)
+	}
+	return conn, nil
+}
+
+// setKeepAlive enables TCP keep alive on the connection.
+// When we set up a TCP connection for hijack, there could be long periods
+// of inactivity (a long running command with no output) that in certain
+// network setups may cause ECONNTIMEOUT, leaving the client in an unknown
+// state. Setting TCP KeepAlive on the socket connection will prohibit
+// ECONNTIMEOUT unless the socket connection truly is broken
+func setKeepAlive(conn net.Conn) {
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		tcpConn.SetKeepAlive(true)
+		tcpConn.SetKeepAlivePeriod(30 * time.Second)
+	}
+}
+
+func dial(ctx context.Context, proto, addr string, tlsConfig *tls.Config) (net.Conn, error) {
 	if tlsConfig != nil && proto != "unix" && proto != "npipe" {
 		// Notice this isn't Go standard's tls.Dial function
 		return tlsDial(proto, addr, tlsConfig)
@@ -170,5 +405,5 @@ func dial(proto, addr string, tlsConfig *tls.Config) (net.Conn, error) {
 	if proto == "npipe" {
 		return sockets.DialPipe(addr, 32*time.Second)
 	}
-	return net.Dial(proto, addr)
+	return defaultDial(ctx, proto, addr)
 }
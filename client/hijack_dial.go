@@ -0,0 +1,28 @@
+// +build !go1.7
+
+package client
+
+import (
+	"net"
+	"net/http"
+
+	"golang.org/x/net/context"
+)
+
+// transportDial returns the function the transport dials its connections
+// with, or nil if it uses the default dialer. The transports can't dial
+// with a context before go1.7.
+func transportDial(tr *http.Transport) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if tr.Dial == nil {
+		return nil
+	}
+	return func(_ context.Context, network, addr string) (net.Conn, error) {
+		return tr.Dial(network, addr)
+	}
+}
+
+// defaultDial opens a connection with the default dialer.
+// It can't be cancelled by the context before go1.7.
+func defaultDial(_ context.Context, network, addr string) (net.Conn, error) {
+	return net.Dial(network, addr)
+}
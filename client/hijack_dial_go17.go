@@ -0,0 +1,34 @@
+// +build go1.7
+
+package client
+
+import (
+	"net"
+	"net/http"
+
+	"golang.org/x/net/context"
+)
+
+// transportDial returns the function the transport dials its connections
+// with, or nil if it uses the default dialer. DialContext takes precedence
+// over Dial, like in the transport.
+func transportDial(tr *http.Transport) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if tr.DialContext != nil {
+		return func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return tr.DialContext(ctx, network, addr)
+		}
+	}
+	if tr.Dial != nil {
+		return func(_ context.Context, network, addr string) (net.Conn, error) {
+			return tr.Dial(network, addr)
+		}
+	}
+	return nil
+}
+
+// defaultDial opens a connection with the default dialer,
+// giving up when the context is done.
+func defaultDial(ctx context.Context, network, addr string) (net.Conn, error) {
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, addr)
+}
@@ -0,0 +1,71 @@
+// +build go1.7
+
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/docker/engine-api/types"
+)
+
+func TestPostHijackedWithDialContext(t *testing.T) {
+	client, err := NewClientWithOpts(WithHost("tcp://docker.example.com:2375"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dialed := make(chan string, 1)
+	client.client.Transport.(*http.Transport).DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed <- addr
+		conn, server := net.Pipe()
+		go func() {
+			defer server.Close()
+			if _, err := http.ReadRequest(bufio.NewReader(server)); err != nil {
+				return
+			}
+			fmt.Fprint(server, "HTTP/1.1 101 UPGRADED\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\nhello")
+		}()
+		return conn, nil
+	}
+
+	resp, err := client.ContainerAttach(context.Background(), "container_id", types.ContainerAttachOptions{Stream: true, Stdout: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	if addr := <-dialed; addr != "docker.example.com:2375" {
+		t.Fatalf("expected a connection to docker.example.com:2375, got %s", addr)
+	}
+	content, err := ioutil.ReadAll(resp.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello" {
+		t.Fatalf("expected the hijacked stream, got %q", string(content))
+	}
+}
+
+func TestPostHijackedCancelDialContext(t *testing.T) {
+	client, err := NewClientWithOpts(WithHost("tcp://docker.example.com:2375"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The dial only ends when its context is done.
+	client.client.Transport.(*http.Transport).DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = client.ContainerAttach(ctx, "container_id", types.ContainerAttachOptions{Stream: true, Stdout: true})
+	if err != context.Canceled {
+		t.Fatalf("expected the context to be canceled, got %v", err)
+	}
+}
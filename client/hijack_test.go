@@ -0,0 +1,193 @@
+package client
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/engine-api/client/transport"
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+)
+
+// dialerMock is a mock transport that dials its own connections.
+type dialerMock struct {
+	transport.Client
+	dial func() (net.Conn, error)
+}
+
+func (d dialerMock) Dial() (net.Conn, error) {
+	return d.dial()
+}
+
+func TestPostHijackedWithDialer(t *testing.T) {
+	errC := make(chan error, 1)
+	client := &Client{
+		proto: "ssh",
+		addr:  "docker@example.com",
+		transport: dialerMock{
+			Client: newMockClient(nil, nil),
+			dial: func() (net.Conn, error) {
+				conn, server := net.Pipe()
+				go func() {
+					defer server.Close()
+					req, err := http.ReadRequest(bufio.NewReader(server))
+					if err != nil {
+						errC <- err
+						return
+					}
+					if req.Host != "docker" || req.URL.Path != "/containers/container_id/attach" {
+						errC <- fmt.Errorf("unexpected request to %s%s", req.Host, req.URL.Path)
+						return
+					}
+					errC <- nil
+					fmt.Fprint(server, "HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.raw-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\nhello")
+				}()
+				return conn, nil
+			},
+		},
+	}
+
+	resp, err := client.ContainerAttach(context.Background(), "container_id", types.ContainerAttachOptions{Stream: true, Stdout: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	if err := <-errC; err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadAll(resp.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello" {
+		t.Fatalf("expected the hijacked stream, got %q", string(content))
+	}
+}
+
+// hijackHandler hijacks the connection, writes the response
+// and waits for the client to close the connection.
+func hijackHandler(response string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer conn.Close()
+		fmt.Fprint(buf, response)
+		buf.Flush()
+		io.Copy(ioutil.Discard, conn)
+	}
+}
+
+func TestPostHijackedCancelUpgrade(t *testing.T) {
+	server, client := newTestServerClient(t, hijackHandler(""))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err := client.ContainerAttach(ctx, "container_id", types.ContainerAttachOptions{Stream: true, Stdout: true})
+	if err != context.Canceled {
+		t.Fatalf("expected the context to be canceled, got %v", err)
+	}
+}
+
+func TestPostHijackedDeadline(t *testing.T) {
+	server, client := newTestServerClient(t, hijackHandler("HTTP/1.1 101 UPGRADED\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\nhello"))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	resp, err := client.ContainerAttach(ctx, "container_id", types.ContainerAttachOptions{Stream: true, Stdout: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+
+	// The stream ends with the error of the context once it's done.
+	content, err := ioutil.ReadAll(resp.Reader)
+	if string(content) != "hello" || err != context.DeadlineExceeded {
+		t.Fatalf("expected the stream to end when the deadline is exceeded, got %q and %v", string(content), err)
+	}
+	if _, err := resp.Conn.Write([]byte("input")); err != context.DeadlineExceeded {
+		t.Fatalf("expected writes to fail when the deadline is exceeded, got %v", err)
+	}
+}
+
+func TestPostHijackedErrorStatus(t *testing.T) {
+	server, client := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message":"No such container: container_id"}`)
+	})
+	defer server.Close()
+
+	_, err := client.ContainerAttach(context.Background(), "container_id", types.ContainerAttachOptions{Stream: true, Stdout: true})
+	if !IsErrNotFound(err) || err.Error() != "Error response from daemon: No such container: container_id" {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
+
+func TestPostHijackedThroughProxy(t *testing.T) {
+	server, _ := newTestServerClient(t, hijackHandler("HTTP/1.1 101 UPGRADED\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\nhello"))
+	defer server.Close()
+	addr := strings.TrimPrefix(server.URL, "http://")
+
+	tunnels := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "CONNECT" || r.Header.Get("Proxy-Authorization") != "Basic dXNlcjpwYXNzd29yZA==" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		backend, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer backend.Close()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tunnels <- r.Host
+		fmt.Fprint(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		go io.Copy(backend, conn)
+		io.Copy(conn, backend)
+	}))
+	defer proxy.Close()
+
+	client, err := NewClientWithOpts(WithHost("tcp://" + addr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxyURL.User = url.UserPassword("user", "password")
+	client.client.Transport.(*http.Transport).Proxy = http.ProxyURL(proxyURL)
+
+	resp, err := client.ContainerAttach(context.Background(), "container_id", types.ContainerAttachOptions{Stream: true, Stdout: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+	if host := <-tunnels; host != addr {
+		t.Fatalf("expected a tunnel to %s, got %s", addr, host)
+	}
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(resp.Reader, buf); err != nil || string(buf) != "hello" {
+		t.Fatalf("expected the hijacked stream, got %q and %v", string(buf), err)
+	}
+}
@@ -18,6 +18,7 @@ import (
 var headerRegexp = regexp.MustCompile(`\ADocker/.+\s\((.+)\)\z`)
 
 // ImageBuild sends request to the daemon to build images.
+// The build context is a tar stream, like the ones created by the buildcontext package.
 // The Body in the response implement an io.ReadCloser and it's up to the caller to
 // close it.
 func (cli *Client) ImageBuild(ctx context.Context, buildContext io.Reader, options types.ImageBuildOptions) (types.ImageBuildResponse, error) {
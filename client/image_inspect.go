@@ -15,7 +15,7 @@ func (cli *Client) ImageInspectWithRaw(ctx context.Context, imageID string) (typ
 	serverResp, err := cli.get(ctx, "/images/"+imageID+"/json", nil, nil)
 	if err != nil {
 		if serverResp.statusCode == http.StatusNotFound {
-			return types.ImageInspect{}, nil, imageNotFoundError{imageID}
+			return types.ImageInspect{}, nil, imageNotFoundError{imageID, err}
 		}
 		return types.ImageInspect{}, nil, err
 	}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
+	"golang.org/x/net/context"
+)
+
+// ImagesPrune removes the dangling images, or all the images that aren't
+// used by any container with the "dangling=false" filter, and reports the
+// space reclaimed. The "until" filter only prunes the images created before
+// a timestamp or duration, and the "label" and "label!" filters the images
+// with, or without, the given labels.
+//
+// The images are removed one by one when the daemon doesn't support the
+// prune endpoint or the filters. The space reclaimed is approximated then,
+// by the size of the deleted images that isn't shared with their parents.
+func (cli *Client) ImagesPrune(ctx context.Context, pruneFilters filters.Args) (types.ImagesPruneReport, error) {
+	var report types.ImagesPruneReport
+	if ok, err := cli.prune(ctx, "/images/prune", pruneFilters, &report); ok || err != nil {
+		return report, err
+	}
+	return cli.pruneImages(ctx, pruneFilters)
+}
+
+// pruneImages prunes the images on the client side.
+func (cli *Client) pruneImages(ctx context.Context, pruneFilters filters.Args) (types.ImagesPruneReport, error) {
+	var report types.ImagesPruneReport
+	f, err := newPruneFilter(pruneFilters, map[string]bool{"dangling": true, "until": true, "label": true, "label!": true})
+	if err != nil {
+		return report, err
+	}
+	danglingOnly, err := pruneDanglingOnly(pruneFilters)
+	if err != nil {
+		return report, err
+	}
+
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{All: true})
+	if err != nil {
+		return report, err
+	}
+	usedImages := make(map[string]bool)
+	for _, c := range containers {
+		usedImages[c.ImageID] = true
+	}
+
+	// The intermediate images are listed too, for the size
+	// of the parents deleted along with their children.
+	images, err := cli.ImageList(ctx, types.ImageListOptions{All: true})
+	if err != nil {
+		return report, err
+	}
+	imagesByID := make(map[string]types.Image)
+	parents := make(map[string]bool)
+	for _, img := range images {
+		imagesByID[img.ID] = img
+		parents[img.ParentID] = true
+	}
+
+	for _, img := range images {
+		if parents[img.ID] || usedImages[img.ID] || !f.match(img.Created, img.Labels) {
+			continue
+		}
+		refs := imageReferences(img)
+		if len(refs) == 0 {
+			refs = []string{img.ID}
+		} else if danglingOnly {
+			continue
+		}
+
+		for _, ref := range refs {
+			deletes, err := cli.ImageRemove(ctx, ref, types.ImageRemoveOptions{PruneChildren: true})
+			if err != nil {
+				if isPruneSkipped(err) {
+					break
+				}
+				return report, err
+			}
+			for _, d := range deletes {
+				if d.Deleted != "" {
+					report.SpaceReclaimed += uniqueImageSize(imagesByID, d.Deleted)
+				}
+			}
+			report.ImagesDeleted = append(report.ImagesDeleted, deletes...)
+		}
+	}
+	return report, nil
+}
+
+// pruneDanglingOnly returns whether only the dangling
+// images are pruned, which is the default.
+func pruneDanglingOnly(pruneFilters filters.Args) (bool, error) {
+	if !pruneFilters.Include("dangling") {
+		return true, nil
+	}
+	if pruneFilters.ExactMatch("dangling", "false") || pruneFilters.ExactMatch("dangling", "0") {
+		return false, nil
+	}
+	if pruneFilters.ExactMatch("dangling", "true") || pruneFilters.ExactMatch("dangling", "1") {
+		return true, nil
+	}
+	return false, fmt.Errorf("Invalid filter 'dangling=%v'", pruneFilters.Get("dangling"))
+}
+
+// imageReferences returns the tags and digests referencing the image,
+// without the "<none>" placeholders of the dangling images.
+func imageReferences(img types.Image) []string {
+	var refs []string
+	for _, tag := range img.RepoTags {
+		if tag != "<none>:<none>" {
+			refs = append(refs, tag)
+		}
+	}
+	for _, digest := range img.RepoDigests {
+		if digest != "<none>@<none>" {
+			refs = append(refs, digest)
+		}
+	}
+	return refs
+}
+
+// uniqueImageSize returns the size of the image that isn't shared
+// with its parent, or its size if the parent isn't known.
+func uniqueImageSize(imagesByID map[string]types.Image, imageID string) uint64 {
+	img, ok := imagesByID[imageID]
+	if !ok {
+		return 0
+	}
+	size := img.Size
+	if parent, ok := imagesByID[img.ParentID]; ok && parent.Size <= size {
+		size -= parent.Size
+	}
+	return uint64(size)
+}
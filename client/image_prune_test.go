@@ -0,0 +1,138 @@
+package client
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
+	"golang.org/x/net/context"
+)
+
+func TestImagesPruneError(t *testing.T) {
+	client := &Client{
+		transport: newMockClient(nil, errorMock(http.StatusInternalServerError, "Server error")),
+		version:   "1.25",
+	}
+
+	_, err := client.ImagesPrune(context.Background(), filters.NewArgs())
+	if err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+}
+
+func TestImagesPrune(t *testing.T) {
+	pruneFilters := filters.NewArgs()
+	pruneFilters.Add("dangling", "false")
+
+	var filterJSON string
+	client := &Client{
+		transport: newMockClient(nil, func(req *http.Request) (*http.Response, error) {
+			filterJSON = req.URL.Query().Get("filters")
+			return pruneMock(map[string]interface{}{
+				"POST /v1.25/images/prune": types.ImagesPruneReport{
+					ImagesDeleted:  []types.ImageDelete{{Untagged: "app:1"}, {Deleted: "image_id"}},
+					SpaceReclaimed: 1024,
+				},
+			}, new([]string))(req)
+		}),
+		version: "1.25",
+	}
+
+	report, err := client.ImagesPrune(context.Background(), pruneFilters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filterJSON != `{"dangling":{"false":true}}` {
+		t.Fatalf("expected the dangling filter, got %s", filterJSON)
+	}
+	if len(report.ImagesDeleted) != 2 || report.SpaceReclaimed != 1024 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
+
+func TestImagesPruneFallback(t *testing.T) {
+	containers := []types.Container{
+		{ID: "container_id", ImageID: "sha256:used"},
+	}
+	images := []types.Image{
+		{ID: "sha256:base", RepoTags: []string{"base:latest"}, Size: 100},
+		{ID: "sha256:mid", ParentID: "sha256:base", Size: 150},
+		{ID: "sha256:dangling", ParentID: "sha256:mid", RepoTags: []string{"<none>:<none>"}, Size: 180},
+		{ID: "sha256:used", Size: 200},
+		{ID: "sha256:tagged", RepoTags: []string{"app:1", "app:2"}, Size: 500},
+	}
+	responses := map[string]interface{}{
+		"GET /v1.24/containers/json":           containers,
+		"GET /v1.24/images/json":               images,
+		"DELETE /v1.24/images/sha256:dangling": []types.ImageDelete{{Deleted: "sha256:dangling"}, {Deleted: "sha256:mid"}},
+		"DELETE /v1.24/images/app:1":           []types.ImageDelete{{Untagged: "app:1"}},
+		"DELETE /v1.24/images/app:2":           []types.ImageDelete{{Untagged: "app:2"}, {Deleted: "sha256:tagged"}},
+	}
+
+	allFilters := filters.NewArgs()
+	allFilters.Add("dangling", "false")
+
+	cases := []struct {
+		filters        filters.Args
+		requests       []string
+		deleted        int
+		spaceReclaimed uint64
+	}{
+		{
+			filters: filters.NewArgs(),
+			requests: []string{
+				"GET /v1.24/containers/json",
+				"GET /v1.24/images/json",
+				"DELETE /v1.24/images/sha256:dangling",
+			},
+			deleted:        2,
+			spaceReclaimed: 80,
+		},
+		{
+			filters: allFilters,
+			requests: []string{
+				"GET /v1.24/containers/json",
+				"GET /v1.24/images/json",
+				"DELETE /v1.24/images/sha256:dangling",
+				"DELETE /v1.24/images/app:1",
+				"DELETE /v1.24/images/app:2",
+			},
+			deleted:        5,
+			spaceReclaimed: 580,
+		},
+	}
+	for _, c := range cases {
+		var requests []string
+		client := &Client{
+			transport: newMockClient(nil, pruneMock(responses, &requests)),
+			version:   "1.24",
+		}
+
+		report, err := client.ImagesPrune(context.Background(), c.filters)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(requests, c.requests) {
+			t.Fatalf("expected requests %v, got %v", c.requests, requests)
+		}
+		if len(report.ImagesDeleted) != c.deleted || report.SpaceReclaimed != c.spaceReclaimed {
+			t.Fatalf("expected %d deleted images and %d bytes reclaimed, got %+v", c.deleted, c.spaceReclaimed, report)
+		}
+	}
+}
+
+func TestImagesPruneFallbackInvalidDangling(t *testing.T) {
+	pruneFilters := filters.NewArgs()
+	pruneFilters.Add("dangling", "maybe")
+
+	client := &Client{
+		transport: newMockClient(nil, pruneMock(nil, new([]string))),
+		version:   "1.24",
+	}
+	_, err := client.ImagesPrune(context.Background(), pruneFilters)
+	if err == nil || err.Error() != "Invalid filter 'dangling=[maybe]'" {
+		t.Fatalf("expected an invalid filter error, got %v", err)
+	}
+}
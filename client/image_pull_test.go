@@ -160,6 +160,18 @@ func TestImagePullWithoutErrors(t *testing.T) {
 			expectedImage: "myimage",
 			expectedTag:   "",
 		},
+		{
+			all:           false,
+			reference:     "docker.io/library/myimage@sha256:ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+			expectedImage: "myimage",
+			expectedTag:   "sha256:ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		},
+		{
+			all:           false,
+			reference:     "test.com:5000/user/myimage:tag@sha256:ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+			expectedImage: "test.com:5000/user/myimage",
+			expectedTag:   "sha256:ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		},
 	}
 	for _, pullCase := range pullCases {
 		client := &Client{
@@ -146,6 +146,11 @@ func TestImagePushWithoutErrors(t *testing.T) {
 			expectedImage: "myimage",
 			expectedTag:   "tag",
 		},
+		{
+			reference:     "index.docker.io/user/myimage:tag",
+			expectedImage: "user/myimage",
+			expectedTag:   "tag",
+		},
 	}
 	for _, pullCase := range pullCases {
 		client := &Client{
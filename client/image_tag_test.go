@@ -89,6 +89,12 @@ func TestImageTag(t *testing.T) {
 				"repo": "test:5000/test/another_repository",
 				"tag":  "latest",
 			},
+		}, {
+			reference: "docker.io/library/repository",
+			expectedQueryParams: map[string]string{
+				"repo": "repository",
+				"tag":  "latest",
+			},
 		},
 	}
 	for _, tagCase := range tagCases {
@@ -0,0 +1,31 @@
+package client
+
+import "net/http"
+
+// RequestFunc sends a request to the docker server and returns its response.
+type RequestFunc func(*http.Request) (*http.Response, error)
+
+// Do sends the request calling the function itself.
+// It allows to use a RequestFunc as a transport.Sender.
+func (f RequestFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps the function that sends requests to the docker server.
+// It can observe and modify every request before calling next, and
+// every response after next returns.
+//
+// Hijacked requests, like attach and exec, go through the middlewares too.
+// The body of their responses is the raw stream of the connection and
+// middlewares must not read from it.
+type Middleware func(next RequestFunc) RequestFunc
+
+// withMiddlewares returns a function that sends requests through the middlewares
+// configured in the client before sending them with send.
+// The first middleware configured is the first one to see the request.
+func (cli *Client) withMiddlewares(send RequestFunc) RequestFunc {
+	for i := len(cli.middlewares) - 1; i >= 0; i-- {
+		send = cli.middlewares[i](send)
+	}
+	return send
+}
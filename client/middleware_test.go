@@ -0,0 +1,159 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/engine-api/types"
+)
+
+func TestMiddlewaresOrder(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next RequestFunc) RequestFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" request")
+				req.Header.Add("X-Middleware", name)
+				resp, err := next(req)
+				calls = append(calls, name+" response")
+				return resp, err
+			}
+		}
+	}
+
+	client := &Client{
+		transport: newMockClient(nil, func(req *http.Request) (*http.Response, error) {
+			calls = append(calls, "transport")
+			if header := strings.Join(req.Header["X-Middleware"], ","); header != "first,second" {
+				return nil, fmt.Errorf("expected headers from both middlewares, got %s", header)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte("{}"))),
+			}, nil
+		}),
+		middlewares: []Middleware{record("first"), record("second")},
+	}
+
+	if _, err := client.Info(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	expected := "first request,second request,transport,second response,first response"
+	if actual := strings.Join(calls, ","); actual != expected {
+		t.Fatalf("expected calls %s, got %s", expected, actual)
+	}
+}
+
+func TestMiddlewareModifiesResponse(t *testing.T) {
+	client := &Client{
+		transport: newMockClient(nil, errorMock(http.StatusServiceUnavailable, "Server error")),
+		middlewares: []Middleware{
+			func(next RequestFunc) RequestFunc {
+				return func(req *http.Request) (*http.Response, error) {
+					resp, err := next(req)
+					if err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+						return resp, err
+					}
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"ID":"recovered"}`))),
+					}, nil
+				}
+			},
+		},
+	}
+
+	info, err := client.Info(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ID != "recovered" {
+		t.Fatalf("expected the response from the middleware, got %v", info)
+	}
+}
+
+func TestMiddlewareInjectsFault(t *testing.T) {
+	fault := errors.New("injected fault")
+	client := &Client{
+		transport: newMockClient(nil, func(req *http.Request) (*http.Response, error) {
+			return nil, fmt.Errorf("the request should not reach the transport")
+		}),
+		middlewares: []Middleware{
+			func(next RequestFunc) RequestFunc {
+				return func(req *http.Request) (*http.Response, error) {
+					return nil, fault
+				}
+			},
+		},
+	}
+
+	_, err := client.Info(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "injected fault") {
+		t.Fatalf("expected the injected fault, got %v", err)
+	}
+}
+
+func TestMiddlewareHijackedRequests(t *testing.T) {
+	server, _ := newTestServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Middleware") != "hijack" {
+			http.Error(w, "missing middleware header", http.StatusBadRequest)
+			return
+		}
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer conn.Close()
+		fmt.Fprint(buf, "HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.raw-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\nhello")
+		buf.Flush()
+	})
+	defer server.Close()
+
+	var (
+		path   string
+		status int
+	)
+	client, err := NewClient("tcp://"+strings.TrimPrefix(server.URL, "http://"), "1.24", nil, nil,
+		func(next RequestFunc) RequestFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				path = req.URL.Path
+				req.Header.Set("X-Middleware", "hijack")
+				resp, err := next(req)
+				if resp != nil {
+					status = resp.StatusCode
+				}
+				return resp, err
+			}
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client.ContainerAttach(context.Background(), "container_id", types.ContainerAttachOptions{Stream: true, Stdout: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Close()
+
+	if path != "/v1.24/containers/container_id/attach" {
+		t.Fatalf("expected the middleware to see the attach request, got %s", path)
+	}
+	if status != http.StatusSwitchingProtocols {
+		t.Fatalf("expected the middleware to see the upgrade response, got %d", status)
+	}
+	content, err := ioutil.ReadAll(resp.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello" {
+		t.Fatalf("expected the hijacked stream, got %q", string(content))
+	}
+}
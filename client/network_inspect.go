@@ -22,7 +22,7 @@ func (cli *Client) NetworkInspectWithRaw(ctx context.Context, networkID string)
 	resp, err := cli.get(ctx, "/networks/"+networkID, nil, nil)
 	if err != nil {
 		if resp.statusCode == http.StatusNotFound {
-			return networkResource, nil, networkNotFoundError{networkID}
+			return networkResource, nil, networkNotFoundError{networkID, err}
 		}
 		return networkResource, nil, err
 	}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
+	"golang.org/x/net/context"
+)
+
+// predefinedNetworks are the networks created
+// by the daemon, which are never pruned.
+var predefinedNetworks = map[string]bool{
+	"bridge":  true,
+	"host":    true,
+	"none":    true,
+	"default": true,
+	"nat":     true,
+	"ingress": true,
+}
+
+// NetworksPrune removes the networks that aren't used by any container.
+// The "until" filter only prunes the networks created before a timestamp
+// or duration, and the "label" and "label!" filters the networks with, or
+// without, the given labels.
+//
+// The networks are removed one by one when the daemon doesn't support
+// the prune endpoint or the filters. Daemons prior to API 1.25 don't report
+// when the networks were created then, the "until" filter prunes none.
+func (cli *Client) NetworksPrune(ctx context.Context, pruneFilters filters.Args) (types.NetworksPruneReport, error) {
+	var report types.NetworksPruneReport
+	if ok, err := cli.prune(ctx, "/networks/prune", pruneFilters, &report); ok || err != nil {
+		return report, err
+	}
+	return cli.pruneNetworks(ctx, pruneFilters)
+}
+
+// pruneNetworks prunes the networks on the client side.
+func (cli *Client) pruneNetworks(ctx context.Context, pruneFilters filters.Args) (types.NetworksPruneReport, error) {
+	var report types.NetworksPruneReport
+	f, err := newPruneFilter(pruneFilters, map[string]bool{"until": true, "label": true, "label!": true})
+	if err != nil {
+		return report, err
+	}
+
+	networks, err := cli.NetworkList(ctx, types.NetworkListOptions{})
+	if err != nil {
+		return report, err
+	}
+	for _, nw := range networks {
+		// The labels are matched before inspecting the networks.
+		if predefinedNetworks[nw.Name] || !f.match(0, nw.Labels) {
+			continue
+		}
+		// The list doesn't include the containers of
+		// the networks since API 1.25, they're inspected.
+		nw, err := cli.NetworkInspect(ctx, nw.ID)
+		if err != nil {
+			if IsErrNetworkNotFound(err) {
+				// The network was removed since it was listed.
+				continue
+			}
+			return report, err
+		}
+		if len(nw.Containers) > 0 || !f.match(nw.Created.Unix(), nw.Labels) {
+			continue
+		}
+		if nw.Created.IsZero() && !f.until.IsZero() {
+			// The daemon doesn't report when the network was created.
+			continue
+		}
+		// The networks used by services can't be removed, they're skipped.
+		if err := cli.NetworkRemove(ctx, nw.ID); err != nil {
+			if isPruneSkipped(err) {
+				continue
+			}
+			return report, err
+		}
+		report.NetworksDeleted = append(report.NetworksDeleted, nw.Name)
+	}
+	return report, nil
+}
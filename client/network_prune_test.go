@@ -0,0 +1,156 @@
+package client
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
+	"golang.org/x/net/context"
+)
+
+func TestNetworksPruneError(t *testing.T) {
+	client := &Client{
+		transport: newMockClient(nil, errorMock(http.StatusInternalServerError, "Server error")),
+		version:   "1.25",
+	}
+
+	_, err := client.NetworksPrune(context.Background(), filters.NewArgs())
+	if err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+}
+
+func TestNetworksPrune(t *testing.T) {
+	pruneFilters := filters.NewArgs()
+	pruneFilters.Add("until", "24h")
+
+	var requests []string
+	client := &Client{
+		transport: newMockClient(nil, pruneMock(map[string]interface{}{
+			"POST /v1.28/networks/prune": types.NetworksPruneReport{
+				NetworksDeleted: []string{"network1"},
+			},
+		}, &requests)),
+		version: "1.28",
+	}
+
+	report, err := client.NetworksPrune(context.Background(), pruneFilters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report.NetworksDeleted, []string{"network1"}) {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
+
+func TestNetworksPruneFallback(t *testing.T) {
+	pruneFilters := filters.NewArgs()
+	pruneFilters.Add("label", "env=test")
+
+	var requests []string
+	client := &Client{
+		transport: newMockClient(nil, pruneMock(map[string]interface{}{
+			"GET /v1.24/networks": []types.NetworkResource{
+				{ID: "bridge_id", Name: "bridge", Labels: map[string]string{"env": "test"}},
+				{ID: "network1_id", Name: "network1", Labels: map[string]string{"env": "test"}},
+				{ID: "network2_id", Name: "network2", Labels: map[string]string{"env": "prod"}},
+				{ID: "network3_id", Name: "network3", Labels: map[string]string{"env": "test"}},
+				{ID: "service_network_id", Name: "service_network", Labels: map[string]string{"env": "test"}},
+				{ID: "removed_id", Name: "removed", Labels: map[string]string{"env": "test"}},
+			},
+			"GET /v1.24/networks/network1_id": types.NetworkResource{ID: "network1_id", Name: "network1", Labels: map[string]string{"env": "test"}},
+			"GET /v1.24/networks/network3_id": types.NetworkResource{
+				ID:         "network3_id",
+				Name:       "network3",
+				Labels:     map[string]string{"env": "test"},
+				Containers: map[string]types.EndpointResource{"container_id": {}},
+			},
+			"GET /v1.24/networks/service_network_id":    types.NetworkResource{ID: "service_network_id", Name: "service_network", Labels: map[string]string{"env": "test"}},
+			"DELETE /v1.24/networks/network1_id":        "",
+			"DELETE /v1.24/networks/service_network_id": http.StatusForbidden,
+		}, &requests)),
+		version: "1.24",
+	}
+
+	report, err := client.NetworksPrune(context.Background(), pruneFilters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRequests := []string{
+		"GET /v1.24/networks",
+		"GET /v1.24/networks/network1_id",
+		"DELETE /v1.24/networks/network1_id",
+		"GET /v1.24/networks/network3_id",
+		"GET /v1.24/networks/service_network_id",
+		"DELETE /v1.24/networks/service_network_id",
+		"GET /v1.24/networks/removed_id",
+	}
+	if !reflect.DeepEqual(requests, expectedRequests) {
+		t.Fatalf("expected requests %v, got %v", expectedRequests, requests)
+	}
+	if !reflect.DeepEqual(report.NetworksDeleted, []string{"network1"}) {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
+
+func TestNetworksPruneFallbackRemoveError(t *testing.T) {
+	client := &Client{
+		transport: newMockClient(nil, pruneMock(map[string]interface{}{
+			"GET /v1.24/networks":                []types.NetworkResource{{ID: "network1_id", Name: "network1"}},
+			"GET /v1.24/networks/network1_id":    types.NetworkResource{ID: "network1_id", Name: "network1"},
+			"DELETE /v1.24/networks/network1_id": http.StatusInternalServerError,
+		}, new([]string))),
+		version: "1.24",
+	}
+
+	_, err := client.NetworksPrune(context.Background(), filters.NewArgs())
+	if err == nil || err.Error() != "Error response from daemon: error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+}
+
+func TestNetworksPruneFallbackUntil(t *testing.T) {
+	pruneFilters := filters.NewArgs()
+	pruneFilters.Add("until", "24h")
+
+	old := time.Now().Add(-48 * time.Hour)
+	cases := []struct {
+		version         string
+		expectedDeleted []string
+	}{
+		{version: "1.25", expectedDeleted: []string{"old"}},
+		// The daemon doesn't report when the networks were created.
+		{version: "1.24"},
+	}
+	for _, c := range cases {
+		prefix := "/v" + c.version
+		networks := []types.NetworkResource{
+			{ID: "old_id", Name: "old"},
+			{ID: "recent_id", Name: "recent"},
+		}
+		if c.version == "1.25" {
+			networks[0].Created = old
+			networks[1].Created = time.Now()
+		}
+		client := &Client{
+			transport: newMockClient(nil, pruneMock(map[string]interface{}{
+				"GET " + prefix + "/networks":           networks,
+				"GET " + prefix + "/networks/old_id":    networks[0],
+				"GET " + prefix + "/networks/recent_id": networks[1],
+				"DELETE " + prefix + "/networks/old_id": "",
+			}, new([]string))),
+			version: c.version,
+		}
+
+		report, err := client.NetworksPrune(context.Background(), pruneFilters)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(report.NetworksDeleted, c.expectedDeleted) {
+			t.Fatalf("expected the networks %v to be deleted with API %s, got %v", c.expectedDeleted, c.version, report.NetworksDeleted)
+		}
+	}
+}
@@ -15,7 +15,7 @@ func (cli *Client) NodeInspectWithRaw(ctx context.Context, nodeID string) (swarm
 	serverResp, err := cli.get(ctx, "/nodes/"+nodeID, nil, nil)
 	if err != nil {
 		if serverResp.statusCode == http.StatusNotFound {
-			return swarm.Node{}, nil, nodeNotFoundError{nodeID}
+			return swarm.Node{}, nil, nodeNotFoundError{nodeID, err}
 		}
 		return swarm.Node{}, nil, err
 	}
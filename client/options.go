@@ -0,0 +1,262 @@
+package client
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/engine-api/cliconfig"
+	"github.com/docker/go-connections/sockets"
+	"github.com/docker/go-connections/tlsconfig"
+)
+
+// Opt is a configuration option to initialize a client with NewClientWithOpts.
+type Opt func(*Client) error
+
+// FromEnv configures the client with the values of the environment variables.
+// Use DOCKER_HOST to set the url to the docker server.
+// Use DOCKER_API_VERSION to set the version of the API to reach.
+// Use DOCKER_CERT_PATH to load the tls certificates from.
+// Use DOCKER_TLS_VERIFY to enable or disable TLS verification, off by default.
+// Use DOCKER_CONFIG to load the configuration file of the docker command line
+// client from, ~/.docker by default, see WithConfigFile. The configuration
+// file is ignored if it can't be loaded.
+// The variables that are not set keep the current configuration of the client.
+func FromEnv(c *Client) error {
+	if dockerCertPath := os.Getenv("DOCKER_CERT_PATH"); dockerCertPath != "" {
+		options := tlsconfig.Options{
+			CAFile:             filepath.Join(dockerCertPath, "ca.pem"),
+			CertFile:           filepath.Join(dockerCertPath, "cert.pem"),
+			KeyFile:            filepath.Join(dockerCertPath, "key.pem"),
+			InsecureSkipVerify: os.Getenv("DOCKER_TLS_VERIFY") == "",
+		}
+		if err := withTLSOptions(c, options); err != nil {
+			return err
+		}
+	}
+
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		if err := WithHost(host)(c); err != nil {
+			return err
+		}
+	}
+
+	if version := os.Getenv("DOCKER_API_VERSION"); version != "" {
+		if err := WithVersion(version)(c); err != nil {
+			return err
+		}
+	}
+
+	// A missing or invalid configuration file doesn't prevent the client
+	// from being configured, its headers are just not added. Use
+	// WithConfigFile to load the configuration file strictly.
+	configFile, err := cliconfig.Load("")
+	if err != nil {
+		return nil
+	}
+	return WithConfigFile(configFile)(c)
+}
+
+// WithConfigFile adds the http headers of the configuration file of the
+// docker command line client to the custom http headers of the client.
+// The headers that are already set take precedence.
+func WithConfigFile(configFile *cliconfig.ConfigFile) Opt {
+	return func(c *Client) error {
+		if configFile == nil {
+			return fmt.Errorf("invalid config file, it must not be nil")
+		}
+		headers := make(map[string]string, len(configFile.HTTPHeaders))
+		for k, v := range configFile.HTTPHeaders {
+			if _, ok := c.customHTTPHeaders[k]; !ok {
+				headers[k] = v
+			}
+		}
+		return WithHTTPHeaders(headers)(c)
+	}
+}
+
+// WithHost sets the url to the docker server,
+// and configures the http client to connect to it.
+func WithHost(host string) Opt {
+	return func(c *Client) error {
+		proto, addr, basePath, err := ParseHost(host)
+		if err != nil {
+			return err
+		}
+		c.host = host
+		c.proto = proto
+		c.addr = addr
+		c.basePath = basePath
+
+		if proto == "ssh" {
+			// The ssh transport dials its own connections.
+			return nil
+		}
+		if tr, ok := c.client.Transport.(*http.Transport); ok {
+			// Protocols that are not available in this platform, like npipe
+			// in unix systems, fail when the client connects to the host.
+			if err := sockets.ConfigureTransport(tr, proto, addr); err != nil && err != sockets.ErrProtocolNotAvailable {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// WithVersion sets the version of the API to reach.
+// An empty version doesn't send any version information.
+func WithVersion(version string) Opt {
+	return func(c *Client) error {
+		if strings.ContainsAny(version, "/?# ") {
+			return fmt.Errorf("invalid API version `%s`", version)
+		}
+		c.version = version
+		return nil
+	}
+}
+
+// WithHTTPClient sets the http client to connect to the server with.
+// Options applied after it, like WithTimeout, modify it.
+func WithHTTPClient(client *http.Client) Opt {
+	return func(c *Client) error {
+		if client == nil {
+			return fmt.Errorf("invalid http client, it must not be nil")
+		}
+		c.client = client
+		return nil
+	}
+}
+
+// WithHTTPHeaders adds custom http headers to add to each request,
+// replacing the headers with the same names that are already set.
+func WithHTTPHeaders(headers map[string]string) Opt {
+	return func(c *Client) error {
+		for k := range headers {
+			if k == "" || strings.ContainsAny(k, " :\r\n") {
+				return fmt.Errorf("invalid http header `%s`", k)
+			}
+		}
+		if len(headers) == 0 {
+			return nil
+		}
+		merged := make(map[string]string, len(c.customHTTPHeaders)+len(headers))
+		for k, v := range c.customHTTPHeaders {
+			merged[k] = v
+		}
+		for k, v := range headers {
+			merged[k] = v
+		}
+		c.customHTTPHeaders = merged
+		return nil
+	}
+}
+
+// WithTLSClientConfig loads the tls certificates from the given files
+// and configures the http client to verify the server with them.
+func WithTLSClientConfig(cacertPath, certPath, keyPath string) Opt {
+	return func(c *Client) error {
+		return withTLSOptions(c, tlsconfig.Options{
+			CAFile:   cacertPath,
+			CertFile: certPath,
+			KeyFile:  keyPath,
+		})
+	}
+}
+
+func withTLSOptions(c *Client, options tlsconfig.Options) error {
+	tr, ok := c.client.Transport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("unable to configure TLS, invalid transport %v", c.client.Transport)
+	}
+	tlsc, err := tlsconfig.Client(options)
+	if err != nil {
+		return err
+	}
+	tr.TLSClientConfig = tlsc
+	return nil
+}
+
+// WithTimeout sets the time limit for the requests sent by the http client.
+// A timeout of zero means no timeout. Hijacked requests, like attach and exec,
+// are not limited by it.
+func WithTimeout(timeout time.Duration) Opt {
+	return func(c *Client) error {
+		if timeout < 0 {
+			return fmt.Errorf("invalid timeout %v, it must not be negative", timeout)
+		}
+		c.client.Timeout = timeout
+		return nil
+	}
+}
+
+// WithDialer sets the dialer to open the connections to tcp and unix hosts with,
+// including the connections of hijacked requests.
+func WithDialer(dialer *net.Dialer) Opt {
+	return func(c *Client) error {
+		if dialer == nil {
+			return fmt.Errorf("invalid dialer, it must not be nil")
+		}
+		c.dialer = dialer
+		return nil
+	}
+}
+
+// useDialer configures the http client to open its connections with the dialer.
+// It's applied once all the options are, so it doesn't depend on their order.
+func (c *Client) useDialer() error {
+	if c.proto != "tcp" && c.proto != "unix" {
+		return fmt.Errorf("unable to use a dialer to connect to %s hosts", c.proto)
+	}
+	tr, ok := c.client.Transport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("unable to use a dialer, invalid transport %v", c.client.Transport)
+	}
+	if c.proto == "unix" {
+		addr := c.addr
+		tr.Dial = func(_, _ string) (net.Conn, error) {
+			return c.dialer.Dial("unix", addr)
+		}
+		return nil
+	}
+	tr.Dial = c.dialer.Dial
+	return nil
+}
+
+// WithScheme sets the protocol scheme to send the requests with,
+// http or https. It defaults to https when the client uses TLS.
+func WithScheme(scheme string) Opt {
+	return func(c *Client) error {
+		if scheme != "http" && scheme != "https" {
+			return fmt.Errorf("invalid scheme `%s`, it must be http or https", scheme)
+		}
+		c.scheme = scheme
+		return nil
+	}
+}
+
+// WithMiddlewares sets the middlewares to send each request through.
+func WithMiddlewares(middlewares ...Middleware) Opt {
+	return func(c *Client) error {
+		for _, m := range middlewares {
+			if m == nil {
+				return fmt.Errorf("invalid middleware, it must not be nil")
+			}
+		}
+		c.middlewares = middlewares
+		return nil
+	}
+}
+
+// WithAPIVersionNegotiation makes the client negotiate the API version
+// with the daemon before sending its first request.
+// See EnableAPIVersionNegotiation.
+func WithAPIVersionNegotiation() Opt {
+	return func(c *Client) error {
+		c.negotiateVersion = true
+		return nil
+	}
+}
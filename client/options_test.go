@@ -0,0 +1,177 @@
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestNewClientWithOpts(t *testing.T) {
+	recoverEnvs := setupEnvs(t, map[string]string{
+		"DOCKER_HOST":        "tcp://localhost:2375/base",
+		"DOCKER_API_VERSION": "1.22",
+		"DOCKER_CERT_PATH":   "testdata/",
+	})
+	defer recoverEnvs(t)
+
+	client, err := NewClientWithOpts(FromEnv, WithTimeout(10*time.Second), WithHTTPHeaders(map[string]string{"User-Agent": "test"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.proto != "tcp" || client.addr != "localhost:2375" || client.basePath != "/base" {
+		t.Fatalf("expected the host from the environment, got %s://%s%s", client.proto, client.addr, client.basePath)
+	}
+	if client.ClientVersion() != "1.22" {
+		t.Fatalf("expected the version from the environment, got %s", client.ClientVersion())
+	}
+	if client.client.Timeout != 10*time.Second {
+		t.Fatalf("expected a timeout of 10s, got %v", client.client.Timeout)
+	}
+	if client.getScheme() != "https" || client.transport.TLSConfig() == nil {
+		t.Fatalf("expected a TLS client from the environment")
+	}
+	if client.customHTTPHeaders["User-Agent"] != "test" {
+		t.Fatalf("expected the custom headers, got %v", client.customHTTPHeaders)
+	}
+}
+
+func TestNewClientWithOptsDefaults(t *testing.T) {
+	client, err := NewClientWithOpts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.host != DefaultDockerHost || client.ClientVersion() != DefaultVersion {
+		t.Fatalf("expected the default host and version, got %s and %s", client.host, client.ClientVersion())
+	}
+	if client.getScheme() != "http" {
+		t.Fatalf("expected the http scheme, got %s", client.getScheme())
+	}
+
+	client, err = NewClientWithOpts(WithTLSClientConfig("testdata/ca.pem", "testdata/cert.pem", "testdata/key.pem"), WithScheme("http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.transport.TLSConfig() == nil || client.getScheme() != "http" {
+		t.Fatalf("expected a TLS client with the http scheme, got %s", client.getScheme())
+	}
+}
+
+func TestNewClientWithOptsErrors(t *testing.T) {
+	cases := []struct {
+		opt           Opt
+		expectedError string
+	}{
+		{opt: WithHost("host"), expectedError: "unable to parse docker host `host`"},
+		{opt: WithVersion("1.24/containers"), expectedError: "invalid API version `1.24/containers`"},
+		{opt: WithHTTPClient(nil), expectedError: "invalid http client, it must not be nil"},
+		{opt: WithHTTPHeaders(map[string]string{"Bad Header": "value"}), expectedError: "invalid http header `Bad Header`"},
+		{opt: WithTLSClientConfig("invalid/ca.pem", "invalid/cert.pem", "invalid/key.pem"), expectedError: "could not read CA certificate"},
+		{opt: WithTimeout(-time.Second), expectedError: "invalid timeout -1s, it must not be negative"},
+		{opt: WithDialer(nil), expectedError: "invalid dialer, it must not be nil"},
+		{opt: WithScheme("ftp"), expectedError: "invalid scheme `ftp`, it must be http or https"},
+		{opt: WithMiddlewares(nil), expectedError: "invalid middleware, it must not be nil"},
+	}
+	for _, c := range cases {
+		_, err := NewClientWithOpts(c.opt)
+		if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+			t.Fatalf("expected an error containing %q, got %v", c.expectedError, err)
+		}
+	}
+
+	_, err := NewClientWithOpts(WithHost("npipe:////./pipe/docker_engine"), WithDialer(&net.Dialer{}))
+	if err == nil || err.Error() != "unable to use a dialer to connect to npipe hosts" {
+		t.Fatalf("expected a dialer error, got %v", err)
+	}
+}
+
+func TestNewClientWithOptsDialer(t *testing.T) {
+	// Reserve a local port for the dialer to connect from.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	localAddr := l.Addr().(*net.TCPAddr)
+	l.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.RemoteAddr != localAddr.String() {
+			http.Error(w, "unexpected remote address "+r.RemoteAddr, http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, "{}")
+	}))
+	defer server.Close()
+
+	// The dialer is used even if the host is set after it.
+	client, err := NewClientWithOpts(
+		WithDialer(&net.Dialer{LocalAddr: localAddr}),
+		WithHost("tcp://"+strings.TrimPrefix(server.URL, "http://")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Info(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFromEnvConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "client-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config := `{"HttpHeaders":{"User-Agent":"from-config","X-Meta":"config"}}`
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	recoverEnvs := setupEnvs(t, map[string]string{"DOCKER_CONFIG": dir})
+	defer recoverEnvs(t)
+
+	client, err := NewClientWithOpts(WithHTTPHeaders(map[string]string{"User-Agent": "explicit"}), FromEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.customHTTPHeaders["User-Agent"] != "explicit" || client.customHTTPHeaders["X-Meta"] != "config" {
+		t.Fatalf("expected the headers of the config file not to override explicit ones, got %v", client.customHTTPHeaders)
+	}
+}
+
+func TestFromEnvCorruptConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "client-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"HttpHeaders":`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	recoverEnvs := setupEnvs(t, map[string]string{
+		"DOCKER_CONFIG": dir,
+		"DOCKER_HOST":   "tcp://localhost:2375",
+	})
+	defer recoverEnvs(t)
+
+	client, err := NewClientWithOpts(FromEnv)
+	if err != nil {
+		t.Fatalf("expected the corrupt config file to be ignored, got %v", err)
+	}
+	if client.host != "tcp://localhost:2375" || len(client.customHTTPHeaders) != 0 {
+		t.Fatalf("expected a client configured without the headers of the config file, got host %s and headers %v", client.host, client.customHTTPHeaders)
+	}
+
+	if _, err := NewEnvClient(); err != nil {
+		t.Fatalf("expected the corrupt config file to be ignored, got %v", err)
+	}
+}
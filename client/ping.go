@@ -0,0 +1,23 @@
+package client
+
+import (
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+)
+
+// Ping pings the server and returns the capabilities it advertises in
+// the headers of the response. It doesn't use the API version of the
+// client, so it can be used before negotiating it.
+func (cli *Client) Ping(ctx context.Context) (types.Ping, error) {
+	var ping types.Ping
+	serverResp, err := cli.getUnversioned(ctx, "/_ping")
+	if err != nil {
+		return ping, err
+	}
+	defer ensureReaderClosed(serverResp)
+
+	ping.APIVersion = serverResp.header.Get("API-Version")
+	ping.Experimental = serverResp.header.Get("Docker-Experimental") == "true"
+	ping.OSType = getDockerOS(serverResp.header.Get("Server"))
+	return ping, nil
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+)
+
+func TestPingError(t *testing.T) {
+	client := &Client{
+		transport: newMockClient(nil, errorMock(http.StatusInternalServerError, "Server error")),
+	}
+	_, err := client.Ping(context.Background())
+	if err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+}
+
+func TestPing(t *testing.T) {
+	cases := []struct {
+		headers  map[string]string
+		expected types.Ping
+	}{
+		{
+			headers: map[string]string{
+				"API-Version":         "1.25",
+				"Docker-Experimental": "true",
+				"Server":              "Docker/1.13.0 (linux)",
+			},
+			expected: types.Ping{APIVersion: "1.25", Experimental: true, OSType: "linux"},
+		},
+		{
+			headers: map[string]string{
+				"Server": "Docker/1.12.0 (windows)",
+			},
+			expected: types.Ping{OSType: "windows"},
+		},
+		{
+			expected: types.Ping{},
+		},
+	}
+	for _, c := range cases {
+		headers := c.headers
+		client := &Client{
+			basePath: "/base",
+			version:  "1.24",
+			transport: newMockClient(nil, func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path != "/base/_ping" {
+					return nil, fmt.Errorf("expected URL '/base/_ping', got %s", req.URL.Path)
+				}
+				header := http.Header{}
+				for k, v := range headers {
+					header.Set(k, v)
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     header,
+					Body:       ioutil.NopCloser(bytes.NewReader([]byte("OK"))),
+				}, nil
+			}),
+		}
+
+		ping, err := client.Ping(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ping != c.expected {
+			t.Fatalf("expected %+v, got %+v", c.expected, ping)
+		}
+	}
+}
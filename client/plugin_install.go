@@ -8,12 +8,19 @@ import (
 	"net/url"
 
 	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/reference"
 	"golang.org/x/net/context"
 )
 
-// PluginInstall installs a plugin
-func (cli *Client) PluginInstall(ctx context.Context, name string, options types.PluginInstallOptions) error {
-	// FIXME(vdemeester) name is a ref, we might want to parse/validate it here.
+// PluginInstall installs a plugin. The name of the plugin is a reference
+// to its image, which is tagged latest if it has neither a tag nor a digest.
+func (cli *Client) PluginInstall(ctx context.Context, ref string, options types.PluginInstallOptions) error {
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return err
+	}
+	name := named.WithDefaultTag().FamiliarString()
+
 	query := url.Values{}
 	query.Set("name", name)
 	resp, err := cli.tryPluginPull(ctx, query, options.RegistryAuth)
@@ -0,0 +1,55 @@
+// +build experimental
+
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/engine-api/types"
+)
+
+func TestPluginInstallReferenceError(t *testing.T) {
+	client := &Client{
+		transport: newMockClient(nil, func(req *http.Request) (*http.Response, error) {
+			return nil, fmt.Errorf("unexpected request to %s", req.URL)
+		}),
+	}
+
+	err := client.PluginInstall(context.Background(), "Plugin_Name", types.PluginInstallOptions{})
+	if err == nil || !strings.Contains(err.Error(), "repository name must be lowercase") {
+		t.Fatalf("expected an invalid reference error, got %v", err)
+	}
+}
+
+func TestPluginInstall(t *testing.T) {
+	var requests []string
+	client := &Client{
+		transport: newMockClient(nil, func(req *http.Request) (*http.Response, error) {
+			requests = append(requests, req.Method+" "+req.URL.Path+"?"+req.URL.RawQuery)
+			body := ""
+			if req.URL.Path == "/plugins/pull" {
+				body = "[]"
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+			}, nil
+		}),
+	}
+
+	err := client.PluginInstall(context.Background(), "docker.io/user/plugin", types.PluginInstallOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "POST /plugins/pull?name=user%2Fplugin%3Alatest POST /plugins/user/plugin:latest/enable?"
+	if actual := fmt.Sprint(requests); actual != "["+expected+"]" {
+		t.Fatalf("expected the requests %s, got %s", expected, actual)
+	}
+}
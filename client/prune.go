@@ -0,0 +1,128 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/docker/engine-api/types/filters"
+	timetypes "github.com/docker/engine-api/types/time"
+	"github.com/docker/engine-api/types/versions"
+	"golang.org/x/net/context"
+)
+
+const (
+	// pruneAPIVersion is the first version of the API with the prune endpoints.
+	pruneAPIVersion = "1.25"
+	// pruneFiltersAPIVersion is the first version of the API
+	// whose prune endpoints support the until and label filters.
+	pruneFiltersAPIVersion = "1.28"
+)
+
+// prune sends the filters to the prune endpoint and decodes its report.
+// It returns false, without error, when the daemon doesn't support the
+// endpoint or the filters: the objects must be pruned on the client side.
+func (cli *Client) prune(ctx context.Context, path string, pruneFilters filters.Args, report interface{}) (bool, error) {
+	if err := cli.negotiateAPIVersionOnce(ctx); err != nil {
+		return false, err
+	}
+	if !cli.supportsPrune(pruneFilters) {
+		return false, nil
+	}
+
+	query := url.Values{}
+	if pruneFilters.Len() > 0 {
+		filterJSON, err := filters.ToParam(pruneFilters)
+		if err != nil {
+			return false, err
+		}
+		query.Set("filters", filterJSON)
+	}
+
+	resp, err := cli.post(ctx, path, query, nil, nil)
+	if err != nil {
+		if IsErrNotFound(err) {
+			// The daemon predates the endpoint.
+			return false, nil
+		}
+		return false, err
+	}
+	err = json.NewDecoder(resp.body).Decode(report)
+	ensureReaderClosed(resp)
+	return true, err
+}
+
+// supportsPrune returns whether the version of the API the client
+// uses has the prune endpoints, and supports the filters.
+func (cli *Client) supportsPrune(pruneFilters filters.Args) bool {
+	version := cli.ClientVersion()
+	if version == "" {
+		return true
+	}
+	if versions.LessThan(version, pruneAPIVersion) {
+		return false
+	}
+	if versions.LessThan(version, pruneFiltersAPIVersion) {
+		for _, field := range []string{"until", "label", "label!"} {
+			if pruneFilters.Include(field) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// pruneFilter matches the objects against the filters
+// of the prune endpoints, to prune them on the client side.
+type pruneFilter struct {
+	filters filters.Args
+	until   time.Time
+}
+
+// newPruneFilter validates the filters against the accepted ones,
+// and parses the until filter.
+func newPruneFilter(pruneFilters filters.Args, accepted map[string]bool) (pruneFilter, error) {
+	if err := pruneFilters.Validate(accepted); err != nil {
+		return pruneFilter{}, err
+	}
+	f := pruneFilter{filters: pruneFilters}
+	if !pruneFilters.Include("until") {
+		return f, nil
+	}
+
+	untilFilters := pruneFilters.Get("until")
+	if len(untilFilters) > 1 {
+		return pruneFilter{}, errors.New("more than one until filter specified")
+	}
+	ts, err := timetypes.GetTimestamp(untilFilters[0], time.Now())
+	if err != nil {
+		return pruneFilter{}, err
+	}
+	seconds, nanoseconds, err := timetypes.ParseTimestamps(ts, 0)
+	if err != nil {
+		return pruneFilter{}, err
+	}
+	f.until = time.Unix(seconds, nanoseconds)
+	return f, nil
+}
+
+// match returns whether an object, created at the given
+// Unix time and with the given labels, is pruned.
+func (f pruneFilter) match(created int64, labels map[string]string) bool {
+	if !f.until.IsZero() && time.Unix(created, 0).After(f.until) {
+		return false
+	}
+	if !f.filters.MatchKVList("label", labels) {
+		return false
+	}
+	// MatchKVList matches any labels when the field isn't set.
+	return !f.filters.Include("label!") || !f.filters.MatchKVList("label!", labels)
+}
+
+// isPruneSkipped returns whether the error removing an object is skipped:
+// like the daemon, the objects it refuses to remove because they're in use
+// aren't pruned.
+func isPruneSkipped(err error) bool {
+	return IsErrConflict(err) || IsErrForbidden(err)
+}
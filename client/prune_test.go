@@ -0,0 +1,114 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/docker/engine-api/types/filters"
+)
+
+// pruneMock replies to the requests with the responses by method and path,
+// and records the requests. An int response is the status code of an error,
+// and the requests without response get a 404 error.
+func pruneMock(responses map[string]interface{}, requests *[]string) func(*http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		key := req.Method + " " + req.URL.Path
+		*requests = append(*requests, key)
+		response, ok := responses[key]
+		if !ok {
+			return errorMock(http.StatusNotFound, "page not found")(req)
+		}
+		if statusCode, ok := response.(int); ok {
+			return errorMock(statusCode, "error")(req)
+		}
+		b, err := json.Marshal(response)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(bytes.NewReader(b)),
+		}, nil
+	}
+}
+
+func TestSupportsPrune(t *testing.T) {
+	labelFilters := filters.NewArgs()
+	labelFilters.Add("label", "env=test")
+	danglingFilters := filters.NewArgs()
+	danglingFilters.Add("dangling", "false")
+
+	cases := []struct {
+		version  string
+		filters  filters.Args
+		expected bool
+	}{
+		{"", labelFilters, true},
+		{"1.24", filters.NewArgs(), false},
+		{"1.25", filters.NewArgs(), true},
+		{"1.25", danglingFilters, true},
+		{"1.25", labelFilters, false},
+		{"1.28", labelFilters, true},
+	}
+	for _, c := range cases {
+		client := &Client{version: c.version}
+		if supported := client.supportsPrune(c.filters); supported != c.expected {
+			t.Fatalf("expected prune support %v for version %q and filters %v, got %v", c.expected, c.version, c.filters, supported)
+		}
+	}
+}
+
+func TestPruneFilterMatch(t *testing.T) {
+	now := time.Now()
+	untilFilters := filters.NewArgs()
+	untilFilters.Add("until", "1h")
+	labelFilters := filters.NewArgs()
+	labelFilters.Add("label", "env=test")
+	notLabelFilters := filters.NewArgs()
+	notLabelFilters.Add("label!", "keep")
+
+	cases := []struct {
+		filters  filters.Args
+		created  time.Time
+		labels   map[string]string
+		expected bool
+	}{
+		{filters.NewArgs(), now, nil, true},
+		{untilFilters, now.Add(-2 * time.Hour), nil, true},
+		{untilFilters, now, nil, false},
+		{labelFilters, now, map[string]string{"env": "test"}, true},
+		{labelFilters, now, map[string]string{"env": "prod"}, false},
+		{labelFilters, now, nil, false},
+		{notLabelFilters, now, nil, true},
+		{notLabelFilters, now, map[string]string{"keep": ""}, false},
+	}
+	accepted := map[string]bool{"until": true, "label": true, "label!": true}
+	for _, c := range cases {
+		f, err := newPruneFilter(c.filters, accepted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if match := f.match(c.created.Unix(), c.labels); match != c.expected {
+			t.Fatalf("expected match %v for filters %v and labels %v, got %v", c.expected, c.filters, c.labels, match)
+		}
+	}
+}
+
+func TestNewPruneFilterErrors(t *testing.T) {
+	invalidFilters := filters.NewArgs()
+	invalidFilters.Add("dangling", "true")
+	if _, err := newPruneFilter(invalidFilters, map[string]bool{"label": true}); err == nil || err.Error() != "Invalid filter 'dangling'" {
+		t.Fatalf("expected an invalid filter error, got %v", err)
+	}
+
+	untilFilters := filters.NewArgs()
+	untilFilters.Add("until", "1h")
+	untilFilters.Add("until", "2h")
+	if _, err := newPruneFilter(untilFilters, map[string]bool{"until": true}); err == nil || err.Error() != "more than one until filter specified" {
+		t.Fatalf("expected an until filter error, got %v", err)
+	}
+}
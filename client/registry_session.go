@@ -0,0 +1,145 @@
+package client
+
+import (
+	"io"
+	"sync"
+
+	"github.com/docker/engine-api/cliconfig/credentials"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/registry"
+	"golang.org/x/net/context"
+)
+
+// RegistryCredentialsFunc returns the credentials to log in a registry with.
+// A registry session calls it every time it needs to authenticate again,
+// so it can prompt the user or read them from a credentials store.
+type RegistryCredentialsFunc func() (types.AuthConfig, error)
+
+// RegistrySession authenticates the requests to a registry with the identity
+// token the registry returns when the client logs in, instead of the password.
+// It logs in once, and again when the daemon reports the token is no longer
+// valid. It's safe to use it from multiple goroutines.
+type RegistrySession struct {
+	cli         *Client
+	credentials RegistryCredentialsFunc
+
+	mu         sync.Mutex
+	authConfig types.AuthConfig
+	loggedIn   bool
+}
+
+// NewRegistrySession returns a session that logs in a registry with
+// the credentials the given function returns.
+func (cli *Client) NewRegistrySession(credentials RegistryCredentialsFunc) *RegistrySession {
+	return &RegistrySession{
+		cli:         cli,
+		credentials: credentials,
+	}
+}
+
+// Login authenticates the docker server with the registry, getting fresh
+// credentials for it. The identity token the registry returns replaces the
+// password, which is not kept. Credentials that already hold an identity
+// token are used as is. It returns an error that satisfies IsErrUnauthorized
+// when the authentication fails.
+func (s *RegistrySession) Login(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.login(ctx)
+}
+
+func (s *RegistrySession) login(ctx context.Context) error {
+	authConfig, err := s.credentials()
+	if err != nil {
+		return err
+	}
+	if authConfig.IdentityToken == "" {
+		response, err := s.cli.RegistryLogin(ctx, authConfig)
+		if err != nil {
+			return err
+		}
+		if response.IdentityToken != "" {
+			authConfig = types.AuthConfig{
+				ServerAddress: authConfig.ServerAddress,
+				IdentityToken: response.IdentityToken,
+			}
+		}
+	}
+	s.authConfig = authConfig
+	s.loggedIn = true
+	return nil
+}
+
+// AuthConfig returns the credentials of the session, logging in
+// if it didn't already. They hold the identity token, if the registry
+// returned one, so callers can save it to log in again later.
+func (s *RegistrySession) AuthConfig(ctx context.Context) (types.AuthConfig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loggedIn {
+		if err := s.login(ctx); err != nil {
+			return types.AuthConfig{}, err
+		}
+	}
+	return s.authConfig, nil
+}
+
+// RegistryAuth returns the encoded credentials of the session to send in
+// the X-Registry-Auth header, logging in if it didn't already.
+func (s *RegistrySession) RegistryAuth(ctx context.Context) (string, error) {
+	authConfig, err := s.AuthConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	return credentials.EncodeAuth(authConfig)
+}
+
+// privilegeFunc returns a function that logs in again and returns the new
+// encoded credentials, to retry the requests the daemon rejects.
+func (s *RegistrySession) privilegeFunc(ctx context.Context) types.RequestPrivilegeFunc {
+	return func() (string, error) {
+		s.mu.Lock()
+		err := s.login(ctx)
+		s.mu.Unlock()
+		if err != nil {
+			return "", err
+		}
+		return s.RegistryAuth(ctx)
+	}
+}
+
+// ImagePull pulls an image with the credentials of the session.
+// See Client.ImagePull.
+func (s *RegistrySession) ImagePull(ctx context.Context, ref string, options types.ImagePullOptions) (io.ReadCloser, error) {
+	registryAuth, err := s.RegistryAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	options.RegistryAuth = registryAuth
+	options.PrivilegeFunc = s.privilegeFunc(ctx)
+	return s.cli.ImagePull(ctx, ref, options)
+}
+
+// ImagePush pushes an image with the credentials of the session.
+// See Client.ImagePush.
+func (s *RegistrySession) ImagePush(ctx context.Context, ref string, options types.ImagePushOptions) (io.ReadCloser, error) {
+	registryAuth, err := s.RegistryAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	options.RegistryAuth = registryAuth
+	options.PrivilegeFunc = s.privilegeFunc(ctx)
+	return s.cli.ImagePush(ctx, ref, options)
+}
+
+// ImageSearch searches the registry with the credentials of the session.
+// See Client.ImageSearch.
+func (s *RegistrySession) ImageSearch(ctx context.Context, term string, options types.ImageSearchOptions) ([]registry.SearchResult, error) {
+	registryAuth, err := s.RegistryAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	options.RegistryAuth = registryAuth
+	options.PrivilegeFunc = s.privilegeFunc(ctx)
+	return s.cli.ImageSearch(ctx, term, options)
+}
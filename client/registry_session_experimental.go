@@ -0,0 +1,20 @@
+// +build experimental
+
+package client
+
+import (
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+)
+
+// PluginInstall installs a plugin with the credentials of the session.
+// See Client.PluginInstall.
+func (s *RegistrySession) PluginInstall(ctx context.Context, name string, options types.PluginInstallOptions) error {
+	registryAuth, err := s.RegistryAuth(ctx)
+	if err != nil {
+		return err
+	}
+	options.RegistryAuth = registryAuth
+	options.PrivilegeFunc = s.privilegeFunc(ctx)
+	return s.cli.PluginInstall(ctx, name, options)
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/engine-api/types"
+)
+
+// registrySessionMock logs in returning a new identity token every time,
+// and rejects the requests authenticated with the tokens in expired.
+func registrySessionMock(logins *int, expired map[string]bool) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/auth") {
+			var authConfig types.AuthConfig
+			if err := json.NewDecoder(req.Body).Decode(&authConfig); err != nil {
+				return nil, err
+			}
+			if authConfig.Password != "secret" {
+				return errorMock(http.StatusUnauthorized, "wrong password")(req)
+			}
+			*logins++
+			body, err := json.Marshal(types.AuthResponse{
+				Status:        "Login Succeeded",
+				IdentityToken: fmt.Sprintf("token-%d", *logins),
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader(body)),
+			}, nil
+		}
+
+		buf, err := base64.URLEncoding.DecodeString(req.Header.Get("X-Registry-Auth"))
+		if err != nil {
+			return nil, err
+		}
+		var authConfig types.AuthConfig
+		if err := json.Unmarshal(buf, &authConfig); err != nil {
+			return nil, err
+		}
+		if authConfig.Password != "" || authConfig.IdentityToken == "" {
+			return nil, fmt.Errorf("expected only an identity token, got %+v", authConfig)
+		}
+		if expired[authConfig.IdentityToken] {
+			return errorMock(http.StatusUnauthorized, "token expired")(req)
+		}
+		body := "[]"
+		if strings.HasSuffix(req.URL.Path, "/images/create") {
+			body = "pulled with " + authConfig.IdentityToken
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+		}, nil
+	}
+}
+
+func registryCredentials(password string) RegistryCredentialsFunc {
+	return func() (types.AuthConfig, error) {
+		return types.AuthConfig{
+			Username:      "user",
+			Password:      password,
+			ServerAddress: "registry.example.com",
+		}, nil
+	}
+}
+
+func TestRegistrySessionLogin(t *testing.T) {
+	var logins int
+	client := &Client{
+		transport: newMockClient(nil, registrySessionMock(&logins, nil)),
+	}
+	session := client.NewRegistrySession(registryCredentials("secret"))
+
+	for i := 0; i < 2; i++ {
+		if _, err := session.ImageSearch(context.Background(), "app", types.ImageSearchOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if logins != 1 {
+		t.Fatalf("expected to log in once, logged in %d times", logins)
+	}
+	authConfig, err := session.AuthConfig(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authConfig.IdentityToken != "token-1" || authConfig.Password != "" || authConfig.ServerAddress != "registry.example.com" {
+		t.Fatalf("expected the identity token instead of the password, got %+v", authConfig)
+	}
+}
+
+func TestRegistrySessionLoginError(t *testing.T) {
+	var logins int
+	client := &Client{
+		transport: newMockClient(nil, registrySessionMock(&logins, nil)),
+	}
+	session := client.NewRegistrySession(registryCredentials("wrong"))
+	_, err := session.ImagePull(context.Background(), "registry.example.com/app", types.ImagePullOptions{})
+	if err == nil || !IsErrUnauthorized(err) {
+		t.Fatalf("expected an unauthorized error, got %v", err)
+	}
+
+	session = client.NewRegistrySession(func() (types.AuthConfig, error) {
+		return types.AuthConfig{}, fmt.Errorf("no credentials")
+	})
+	if err := session.Login(context.Background()); err == nil || err.Error() != "no credentials" {
+		t.Fatalf("expected the error of the credentials function, got %v", err)
+	}
+}
+
+func TestRegistrySessionReauthenticates(t *testing.T) {
+	var logins int
+	client := &Client{
+		transport: newMockClient(nil, registrySessionMock(&logins, map[string]bool{"token-1": true})),
+	}
+	session := client.NewRegistrySession(registryCredentials("secret"))
+
+	body, err := session.ImagePull(context.Background(), "registry.example.com/app", types.ImagePullOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	content, err := ioutil.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "pulled with token-2" || logins != 2 {
+		t.Fatalf("expected to log in again once, got %q after %d logins", string(content), logins)
+	}
+}
+
+func TestRegistrySessionIdentityToken(t *testing.T) {
+	var logins int
+	client := &Client{
+		transport: newMockClient(nil, registrySessionMock(&logins, map[string]bool{"saved": true})),
+	}
+	session := client.NewRegistrySession(func() (types.AuthConfig, error) {
+		return types.AuthConfig{IdentityToken: "saved", ServerAddress: "registry.example.com"}, nil
+	})
+
+	// The saved token is sent without logging in, and the request
+	// fails once the daemon rejects it again.
+	_, err := session.ImagePush(context.Background(), "registry.example.com/app", types.ImagePushOptions{})
+	if err == nil || err.Error() != "Error response from daemon: token expired" {
+		t.Fatalf("expected an unauthorized error, got %v", err)
+	}
+	if logins != 0 {
+		t.Fatalf("expected not to log in, logged in %d times", logins)
+	}
+}
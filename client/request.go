@@ -12,7 +12,6 @@ import (
 	"strings"
 
 	"github.com/docker/engine-api/client/transport/cancellable"
-	"github.com/docker/engine-api/types"
 	"github.com/docker/engine-api/types/versions"
 	"golang.org/x/net/context"
 )
@@ -77,9 +76,16 @@ func (cli *Client) sendRequest(ctx context.Context, method, path string, query u
 }
 
 func (cli *Client) sendClientRequest(ctx context.Context, method, path string, query url.Values, body io.Reader, headers map[string][]string) (serverResponse, error) {
-	serverResp := serverResponse{
-		body:       nil,
-		statusCode: -1,
+	if cli.retryPolicy != nil {
+		return cli.sendWithRetries(ctx, method, path, query, body, headers)
+	}
+	return cli.doRequest(ctx, method, path, query, body, headers)
+}
+
+// doRequest sends a single request to the docker host.
+func (cli *Client) doRequest(ctx context.Context, method, path string, query url.Values, body io.Reader, headers map[string][]string) (serverResponse, error) {
+	if err := cli.negotiateAPIVersionOnce(ctx); err != nil {
+		return serverResponse{statusCode: -1}, err
 	}
 
 	expectedPayload := (method == "POST" || method == "PUT")
@@ -89,7 +95,17 @@ func (cli *Client) sendClientRequest(ctx context.Context, method, path string, q
 
 	req, err := cli.newRequest(method, path, query, body, headers)
 	if err != nil {
-		return serverResp, err
+		return serverResponse{statusCode: -1}, err
+	}
+	return cli.sendHTTPRequest(ctx, req)
+}
+
+// sendHTTPRequest sends a request built with newRequest, or newUnversionedRequest,
+// to the docker host and checks the status code of its response.
+func (cli *Client) sendHTTPRequest(ctx context.Context, req *http.Request) (serverResponse, error) {
+	serverResp := serverResponse{
+		body:       nil,
+		statusCode: -1,
 	}
 
 	if cli.proto == "unix" || cli.proto == "npipe" {
@@ -97,14 +113,15 @@ func (cli *Client) sendClientRequest(ctx context.Context, method, path string, q
 		// need a valid and meaningful host name. (See #189)
 		req.Host = "docker"
 	}
-	req.URL.Host = cli.addr
-	req.URL.Scheme = cli.transport.Scheme()
+	req.URL.Host = cli.urlHost()
+	req.URL.Scheme = cli.getScheme()
 
+	expectedPayload := (req.Method == "POST" || req.Method == "PUT")
 	if expectedPayload && req.Header.Get("Content-Type") == "" {
 		req.Header.Set("Content-Type", "text/plain")
 	}
 
-	resp, err := cancellable.Do(ctx, cli.transport, req)
+	resp, err := cancellable.Do(ctx, cli.withMiddlewares(cli.transport.Do), req)
 	if err != nil {
 		if !cli.transport.Secure() && strings.Contains(err.Error(), "malformed HTTP response") {
 			return serverResp, fmt.Errorf("%v.\n* Are you trying to connect to a TLS-enabled daemon without TLS?", err)
@@ -139,27 +156,7 @@ func (cli *Client) sendClientRequest(ctx context.Context, method, path string, q
 	}
 
 	if serverResp.statusCode < 200 || serverResp.statusCode >= 400 {
-		body, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return serverResp, err
-		}
-		if len(body) == 0 {
-			return serverResp, fmt.Errorf("Error: request returned %s for API route and version %s, check if the server supports the requested API version", http.StatusText(serverResp.statusCode), req.URL)
-		}
-
-		var errorMessage string
-		if (cli.version == "" || versions.GreaterThan(cli.version, "1.23")) &&
-			resp.Header.Get("Content-Type") == "application/json" {
-			var errorResponse types.ErrorResponse
-			if err := json.Unmarshal(body, &errorResponse); err != nil {
-				return serverResp, fmt.Errorf("Error reading JSON: %v", err)
-			}
-			errorMessage = errorResponse.Message
-		} else {
-			errorMessage = string(body)
-		}
-
-		return serverResp, fmt.Errorf("Error response from daemon: %s", strings.TrimSpace(errorMessage))
+		return serverResp, cli.responseError(req, resp)
 	}
 
 	serverResp.body = resp.Body
@@ -167,8 +164,46 @@ func (cli *Client) sendClientRequest(ctx context.Context, method, path string, q
 	return serverResp, nil
 }
 
+// responseError reads the body of a response with an error status code
+// and returns the error the docker host replied to the request with.
+func (cli *Client) responseError(req *http.Request, resp *http.Response) error {
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	serverErr := ServerError{
+		StatusCode: resp.StatusCode,
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		Version:    cli.ClientVersion(),
+	}
+	if len(body) == 0 {
+		return serverErr
+	}
+
+	if (serverErr.Version == "" || versions.GreaterThan(serverErr.Version, "1.23")) &&
+		resp.Header.Get("Content-Type") == "application/json" {
+		if err := json.Unmarshal(body, &serverErr.Response); err != nil {
+			return fmt.Errorf("Error reading JSON: %v", err)
+		}
+	} else {
+		serverErr.Response.Message = string(body)
+	}
+	serverErr.Response.Message = strings.TrimSpace(serverErr.Response.Message)
+	return serverErr
+}
+
 func (cli *Client) newRequest(method, path string, query url.Values, body io.Reader, headers map[string][]string) (*http.Request, error) {
-	apiPath := cli.getAPIPath(path, query)
+	return cli.buildRequest(method, cli.getAPIPath(path, query), body, headers)
+}
+
+// newUnversionedRequest creates a request to a path of the API
+// that doesn't depend on its version, like /_ping.
+func (cli *Client) newUnversionedRequest(method, path string, headers map[string][]string) (*http.Request, error) {
+	return cli.buildRequest(method, cli.basePath+path, nil, headers)
+}
+
+func (cli *Client) buildRequest(method, apiPath string, body io.Reader, headers map[string][]string) (*http.Request, error) {
 	req, err := http.NewRequest(method, apiPath, body)
 	if err != nil {
 		return nil, err
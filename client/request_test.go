@@ -89,3 +89,44 @@ func TestPlainTextError(t *testing.T) {
 		t.Fatalf("expected a Server Error, got %v", err)
 	}
 }
+
+func TestServerError(t *testing.T) {
+	client := &Client{
+		version:   "1.24",
+		transport: newMockClient(nil, errorMock(http.StatusConflict, "You cannot remove a running container")),
+	}
+	err := client.ContainerRemove(context.Background(), "container_id", types.ContainerRemoveOptions{})
+	if !IsErrConflict(err) || IsErrNotFound(err) {
+		t.Fatalf("expected a conflict error, got %v", err)
+	}
+	serverErr, ok := AsServerError(err)
+	if !ok {
+		t.Fatalf("expected a ServerError, got %T", err)
+	}
+	if serverErr.StatusCode != http.StatusConflict || serverErr.Method != "DELETE" || serverErr.Path != "/v1.24/containers/container_id" || serverErr.Version != "1.24" {
+		t.Fatalf("unexpected request details: %+v", serverErr)
+	}
+	if serverErr.Response.Message != "You cannot remove a running container" || err.Error() != "Error response from daemon: You cannot remove a running container" {
+		t.Fatalf("unexpected message: %v", err)
+	}
+}
+
+func TestServerErrorWithoutBody(t *testing.T) {
+	client := &Client{
+		version: "1.24",
+		transport: newMockClient(nil, func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusNotFound,
+				Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+			}, nil
+		}),
+	}
+	_, err := client.ContainerList(context.Background(), types.ContainerListOptions{})
+	if !IsErrNotFound(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+	expected := "Error: request returned Not Found for API route and version /v1.24/containers/json, check if the server supports the requested API version"
+	if err.Error() != expected {
+		t.Fatalf("expected %q, got %q", expected, err.Error())
+	}
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+const (
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff     = 5 * time.Second
+	defaultRetryMultiplier     = 2.0
+)
+
+// RetryPolicy defines how to retry requests that failed
+// because the client could not connect to the docker host,
+// for instance while the daemon restarts.
+//
+// Only GET and HEAD requests are retried by default, other
+// requests are only retried when RetryNonIdempotent is set.
+// Hijacked requests, like attach and exec, are never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request is sent,
+	// including the first attempt. Values lower than 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the time to wait before the first retry,
+	// 100 milliseconds by default.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the time to wait between attempts,
+	// 5 seconds by default.
+	MaxBackoff time.Duration
+	// Multiplier increases the backoff after every attempt, 2 by default.
+	Multiplier float64
+	// Jitter randomizes the backoff by up to this fraction of it,
+	// in both directions. It must be between 0 and 1.
+	Jitter float64
+	// RetryNonIdempotent allows to retry POST, PUT and DELETE requests.
+	RetryNonIdempotent bool
+	// ReplayStreams allows to retry requests with streaming bodies,
+	// like build contexts and image imports, reading them in memory
+	// so they can be sent again. It has no effect without RetryNonIdempotent.
+	ReplayStreams bool
+	// OnRetry is called before every retry.
+	OnRetry func(RetryAttempt)
+}
+
+// RetryAttempt holds information about a request that is going to be retried.
+type RetryAttempt struct {
+	Method string
+	Path   string
+	// Attempt is the number of the attempt that failed, starting at 1.
+	Attempt int
+	// Err is the error of the attempt that failed.
+	Err error
+	// Backoff is the time to wait before the next attempt.
+	Backoff time.Duration
+}
+
+// SetRetryPolicy sets the policy to retry failed requests with.
+// A nil policy disables retries.
+// It's not safe to call it while the client sends requests.
+func (cli *Client) SetRetryPolicy(policy *RetryPolicy) {
+	cli.retryPolicy = policy
+}
+
+// sendWithRetries sends a request retrying it as the retry policy defines.
+func (cli *Client) sendWithRetries(ctx context.Context, method, path string, query url.Values, body io.Reader, headers map[string][]string) (serverResponse, error) {
+	policy := cli.retryPolicy
+	if policy.MaxAttempts < 2 || !policy.canRetry(method) {
+		return cli.doRequest(ctx, method, path, query, body, headers)
+	}
+
+	newBody, err := policy.replayableBody(body)
+	if err != nil {
+		return serverResponse{}, err
+	}
+	if newBody == nil {
+		return cli.doRequest(ctx, method, path, query, body, headers)
+	}
+
+	backoff := policy.initialBackoff()
+	for attempt := 1; ; attempt++ {
+		resp, err := cli.doRequest(ctx, method, path, query, newBody(), headers)
+		if err == nil || !IsErrConnectionFailed(err) || attempt >= policy.MaxAttempts {
+			return resp, err
+		}
+
+		wait := policy.jitter(backoff)
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			// The next attempt would start after the deadline.
+			return resp, err
+		}
+		if policy.OnRetry != nil {
+			policy.OnRetry(RetryAttempt{
+				Method:  method,
+				Path:    path,
+				Attempt: attempt,
+				Err:     err,
+				Backoff: wait,
+			})
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return resp, ctx.Err()
+		}
+		backoff = policy.nextBackoff(backoff)
+	}
+}
+
+// canRetry returns whether requests with the given method can be retried.
+func (p *RetryPolicy) canRetry(method string) bool {
+	switch strings.ToUpper(method) {
+	case "GET", "HEAD":
+		return true
+	}
+	return p.RetryNonIdempotent
+}
+
+// replayableBody returns a function that returns a new reader with the
+// request body for every attempt. It returns nil if the body can't be replayed.
+func (p *RetryPolicy) replayableBody(body io.Reader) (func() io.Reader, error) {
+	if body == nil {
+		return func() io.Reader { return nil }, nil
+	}
+
+	switch body.(type) {
+	case *bytes.Buffer, *bytes.Reader, *strings.Reader:
+		// The body is already in memory, encoded by the client.
+	default:
+		if !p.ReplayStreams {
+			return nil, nil
+		}
+	}
+
+	content, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return func() io.Reader { return bytes.NewReader(content) }, nil
+}
+
+func (p *RetryPolicy) initialBackoff() time.Duration {
+	if p.InitialBackoff > 0 {
+		return p.InitialBackoff
+	}
+	return defaultRetryInitialBackoff
+}
+
+// nextBackoff returns the backoff for the attempt after the one
+// with the given backoff, capped to the maximum backoff.
+func (p *RetryPolicy) nextBackoff(backoff time.Duration) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = defaultRetryMultiplier
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRetryMaxBackoff
+	}
+
+	next := time.Duration(float64(backoff) * multiplier)
+	if next > maxBackoff {
+		next = maxBackoff
+	}
+	return next
+}
+
+// jitter randomizes the backoff by up to the jitter fraction of it.
+func (p *RetryPolicy) jitter(backoff time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return backoff
+	}
+	jitter := p.Jitter
+	if jitter > 1 {
+		jitter = 1
+	}
+	delta := (rand.Float64()*2 - 1) * jitter * float64(backoff)
+	return backoff + time.Duration(delta)
+}
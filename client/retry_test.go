@@ -0,0 +1,240 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/container"
+)
+
+var errConnectionRefused = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+// failingMock fails with connection refused the given number
+// of times before sending the request to the doer.
+func failingMock(failures int, doer func(*http.Request) (*http.Response, error)) (func(*http.Request) (*http.Response, error), *int) {
+	attempts := 0
+	return func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts <= failures {
+			return nil, errConnectionRefused
+		}
+		return doer(req)
+	}, &attempts
+}
+
+func okMock(body string) func(*http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+		}, nil
+	}
+}
+
+func TestRetryIdempotentRequests(t *testing.T) {
+	doer, attempts := failingMock(2, okMock(`{"ID":"daemon"}`))
+	var retries []RetryAttempt
+	client := &Client{
+		host:      "tcp://docker:2375",
+		transport: newMockClient(nil, doer),
+		retryPolicy: &RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			OnRetry: func(r RetryAttempt) {
+				retries = append(retries, r)
+			},
+		},
+	}
+
+	info, err := client.Info(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ID != "daemon" {
+		t.Fatalf("expected info from the daemon, got %v", info)
+	}
+	if *attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", *attempts)
+	}
+	if len(retries) != 2 {
+		t.Fatalf("expected 2 retries reported, got %d", len(retries))
+	}
+	if r := retries[1]; r.Method != "GET" || r.Path != "/info" || r.Attempt != 2 || !IsErrConnectionFailed(r.Err) || r.Backoff != 2*time.Millisecond {
+		t.Fatalf("unexpected retry attempt reported: %+v", r)
+	}
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	doer, attempts := failingMock(5, okMock("{}"))
+	client := &Client{
+		host:        "tcp://docker:2375",
+		transport:   newMockClient(nil, doer),
+		retryPolicy: &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	}
+
+	_, err := client.Info(context.Background())
+	if !IsErrConnectionFailed(err) {
+		t.Fatalf("expected a connection failed error, got %v", err)
+	}
+	if *attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", *attempts)
+	}
+}
+
+func TestRetryDoesNotRetryOtherErrors(t *testing.T) {
+	attempts := 0
+	client := &Client{
+		host: "tcp://docker:2375",
+		transport: newMockClient(nil, func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return errorMock(http.StatusInternalServerError, "Server error")(req)
+		}),
+		retryPolicy: &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	}
+
+	_, err := client.Info(context.Background())
+	if err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected 1 attempt, got %d", attempts)
+	}
+}
+
+func TestRetryNonIdempotentRequests(t *testing.T) {
+	cases := []struct {
+		policy           RetryPolicy
+		expectedAttempts int
+	}{
+		{
+			policy:           RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+			expectedAttempts: 1,
+		},
+		{
+			policy:           RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, RetryNonIdempotent: true},
+			expectedAttempts: 2,
+		},
+	}
+	for _, c := range cases {
+		policy := c.policy
+		doer, attempts := failingMock(1, func(req *http.Request) (*http.Response, error) {
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			if !strings.Contains(string(body), `"Image":"busybox"`) {
+				return nil, fmt.Errorf("expected the request body to be replayed, got %s", string(body))
+			}
+			return okMock(`{"Id":"container_id"}`)(req)
+		})
+		client := &Client{
+			host:        "tcp://docker:2375",
+			transport:   newMockClient(nil, doer),
+			retryPolicy: &policy,
+		}
+
+		_, err := client.ContainerCreate(context.Background(), &container.Config{Image: "busybox"}, nil, nil, "")
+		if *attempts != c.expectedAttempts {
+			t.Fatalf("expected %d attempts, got %d", c.expectedAttempts, *attempts)
+		}
+		if c.expectedAttempts == 1 && !IsErrConnectionFailed(err) {
+			t.Fatalf("expected a connection failed error, got %v", err)
+		}
+		if c.expectedAttempts > 1 && err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRetryStreamingBodies(t *testing.T) {
+	cases := []struct {
+		policy           RetryPolicy
+		expectedAttempts int
+	}{
+		{
+			policy:           RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, RetryNonIdempotent: true},
+			expectedAttempts: 1,
+		},
+		{
+			policy:           RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, RetryNonIdempotent: true, ReplayStreams: true},
+			expectedAttempts: 2,
+		},
+	}
+	for _, c := range cases {
+		policy := c.policy
+		doer, attempts := failingMock(1, func(req *http.Request) (*http.Response, error) {
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			if string(body) != "build context" {
+				return nil, fmt.Errorf("expected the build context to be replayed, got %s", string(body))
+			}
+			return okMock("")(req)
+		})
+		client := &Client{
+			host:        "tcp://docker:2375",
+			transport:   newMockClient(nil, doer),
+			retryPolicy: &policy,
+		}
+
+		// Hide the type of the reader, so it's treated as a stream.
+		buildContext := ioutil.NopCloser(strings.NewReader("build context"))
+		_, err := client.ImageBuild(context.Background(), buildContext, types.ImageBuildOptions{})
+		if *attempts != c.expectedAttempts {
+			t.Fatalf("expected %d attempts, got %d", c.expectedAttempts, *attempts)
+		}
+		if c.expectedAttempts > 1 && err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRetryContextDeadline(t *testing.T) {
+	doer, attempts := failingMock(5, okMock("{}"))
+	client := &Client{
+		host:        "tcp://docker:2375",
+		transport:   newMockClient(nil, doer),
+		retryPolicy: &RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	_, err := client.Info(ctx)
+	if !IsErrConnectionFailed(err) {
+		t.Fatalf("expected a connection failed error, got %v", err)
+	}
+	if *attempts != 1 {
+		t.Fatalf("expected to stop retrying before the deadline, got %d attempts", *attempts)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 3 * time.Second}
+	backoff := policy.initialBackoff()
+	var backoffs []time.Duration
+	for i := 0; i < 3; i++ {
+		backoffs = append(backoffs, backoff)
+		backoff = policy.nextBackoff(backoff)
+	}
+	if fmt.Sprint(backoffs) != "[1s 2s 3s]" {
+		t.Fatalf("expected exponential backoffs capped to 3s, got %v", backoffs)
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if d := policy.jitter(time.Second); d < 500*time.Millisecond || d > 1500*time.Millisecond {
+			t.Fatalf("expected jitter within 50%% of the backoff, got %v", d)
+		}
+	}
+}
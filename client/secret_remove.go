@@ -0,0 +1,10 @@
+package client
+
+import "golang.org/x/net/context"
+
+// SecretRemove removes a Secret.
+func (cli *Client) SecretRemove(ctx context.Context, secretID string) error {
+	resp, err := cli.delete(ctx, "/secrets/"+secretID, nil, nil)
+	ensureReaderClosed(resp)
+	return err
+}
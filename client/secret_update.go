@@ -0,0 +1,19 @@
+package client
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/docker/engine-api/types/swarm"
+	"golang.org/x/net/context"
+)
+
+// SecretUpdate updates a Secret. Only the labels of
+// a secret can be updated, not its name nor its data.
+func (cli *Client) SecretUpdate(ctx context.Context, secretID string, version swarm.Version, secret swarm.SecretSpec) error {
+	query := url.Values{}
+	query.Set("version", strconv.FormatUint(version.Index, 10))
+	resp, err := cli.post(ctx, "/secrets/"+secretID+"/update", query, secret, nil)
+	ensureReaderClosed(resp)
+	return err
+}
@@ -15,7 +15,7 @@ func (cli *Client) ServiceInspectWithRaw(ctx context.Context, serviceID string)
 	serverResp, err := cli.get(ctx, "/services/"+serviceID, nil, nil)
 	if err != nil {
 		if serverResp.statusCode == http.StatusNotFound {
-			return swarm.Service{}, nil, serviceNotFoundError{serviceID}
+			return swarm.Service{}, nil, serviceNotFoundError{serviceID, err}
 		}
 		return swarm.Service{}, nil, err
 	}
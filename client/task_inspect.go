@@ -16,7 +16,7 @@ func (cli *Client) TaskInspectWithRaw(ctx context.Context, taskID string) (swarm
 	serverResp, err := cli.get(ctx, "/tasks/"+taskID, nil, nil)
 	if err != nil {
 		if serverResp.statusCode == http.StatusNotFound {
-			return swarm.Task{}, nil, taskNotFoundError{taskID}
+			return swarm.Task{}, nil, taskNotFoundError{taskID, err}
 		}
 		return swarm.Task{}, nil, err
 	}
@@ -0,0 +1,9 @@
+// +build !go1.7
+
+package transport
+
+import "net/http"
+
+// clearDialContext clears the function the transport dials its connections
+// with in priority. The transports don't have one before go1.7.
+func clearDialContext(tr *http.Transport) {}
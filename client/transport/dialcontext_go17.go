@@ -0,0 +1,11 @@
+// +build go1.7
+
+package transport
+
+import "net/http"
+
+// clearDialContext clears the function the transport dials its connections
+// with in priority, so that it uses its Dial function.
+func clearDialContext(tr *http.Transport) {
+	tr.DialContext = nil
+}
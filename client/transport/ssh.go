@@ -0,0 +1,333 @@
+package transport
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// DefaultSSHSocket is the path of the docker socket in the remote host
+// used when the ssh address doesn't include it.
+const DefaultSSHSocket = "/var/run/docker.sock"
+
+// defaultSSHTimeout bounds the connection to the remote host and the ssh
+// handshake when the configuration doesn't set a timeout.
+const defaultSSHTimeout = 30 * time.Second
+
+// Dialer is implemented by transports that open their own connections
+// to the docker server. Hijacked requests, like attach and exec,
+// use it to connect to the server.
+type Dialer interface {
+	// Dial opens a new connection to the docker server.
+	Dial() (net.Conn, error)
+}
+
+// sshTransport holds information about the http transport
+// that tunnels the connections with the API over ssh.
+type sshTransport struct {
+	*apiTransport
+	tunnel *sshTunnel
+}
+
+// NewSSHTransport creates a new transport that tunnels the requests over
+// ssh to the unix socket of a remote docker server.
+// The address has the form user@host[:port][/path/to/docker.sock].
+// It uses the configuration returned by SSHClientConfig if config is nil,
+// and a timeout of 30 seconds to connect to the host if it doesn't set any.
+func NewSSHTransport(addr string, config *ssh.ClientConfig) (Client, error) {
+	return newSSHTransport(addr, config, nil)
+}
+
+func newSSHTransport(addr string, config *ssh.ClientConfig, client *http.Client) (Client, error) {
+	user, hostPort, socket, err := ParseSSHAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		config, err = SSHClientConfig(user)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Make a copy to avoid modifying the argument.
+		c := *config
+		config = &c
+	}
+	if config.User == "" {
+		config.User = user
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaultSSHTimeout
+	}
+
+	var transport *http.Transport
+	if client != nil {
+		tr, ok := client.Transport.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("unable to tunnel connections over ssh, invalid transport %v", client.Transport)
+		}
+		transport = tr
+	} else {
+		transport = new(http.Transport)
+		client = &http.Client{
+			Transport: transport,
+		}
+	}
+
+	tunnel := &sshTunnel{
+		addr:   hostPort,
+		socket: socket,
+		config: config,
+	}
+	transport.Dial = func(_, _ string) (net.Conn, error) {
+		return tunnel.Dial()
+	}
+	// DialContext takes precedence over Dial, a transport
+	// setting it would bypass the tunnel.
+	clearDialContext(transport)
+
+	return &sshTransport{
+		apiTransport: &apiTransport{
+			Client:    client,
+			tlsInfo:   &tlsInfo{},
+			transport: transport,
+		},
+		tunnel: tunnel,
+	}, nil
+}
+
+// Dial opens a new connection to the remote docker socket.
+func (t *sshTransport) Dial() (net.Conn, error) {
+	return t.tunnel.Dial()
+}
+
+// ParseSSHAddr splits an ssh address in the user, the host and port
+// to connect to, and the path of the remote docker socket.
+// The user defaults to the current user and the port to 22.
+func ParseSSHAddr(addr string) (string, string, string, error) {
+	parsed, err := url.Parse("ssh://" + addr)
+	if err != nil {
+		return "", "", "", err
+	}
+	if parsed.Host == "" {
+		return "", "", "", fmt.Errorf("unable to parse ssh address `%s`, missing host", addr)
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return "", "", "", fmt.Errorf("unable to parse ssh address `%s`, unexpected query or fragment", addr)
+	}
+
+	user := os.Getenv("USER")
+	if parsed.User != nil {
+		if _, ok := parsed.User.Password(); ok {
+			return "", "", "", fmt.Errorf("unable to parse ssh address `%s`, passwords are not supported", addr)
+		}
+		user = parsed.User.Username()
+	}
+	if user == "" {
+		return "", "", "", fmt.Errorf("unable to parse ssh address `%s`, missing user", addr)
+	}
+
+	hostPort := parsed.Host
+	if _, _, err := net.SplitHostPort(hostPort); err != nil {
+		hostPort = net.JoinHostPort(strings.Trim(hostPort, "[]"), "22")
+	}
+
+	socket := parsed.Path
+	if socket == "" || socket == "/" {
+		socket = DefaultSSHSocket
+	}
+	return user, hostPort, socket, nil
+}
+
+// SSHClientConfig returns the ssh configuration to connect to remote hosts as
+// the given user. It verifies the keys of the hosts with ~/.ssh/known_hosts,
+// and authenticates with the ssh agent listening in SSH_AUTH_SOCK, if any,
+// and the unencrypted private keys in ~/.ssh/id_ed25519, id_ecdsa and id_rsa.
+// The agent is only connected to while the connections are authenticated.
+func SSHClientConfig(user string) (*ssh.ClientConfig, error) {
+	sshDir := filepath.Join(homeDir(), ".ssh")
+	hostKeyCallback, err := knownhosts.New(filepath.Join(sshDir, "known_hosts"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the known ssh hosts: %v", err)
+	}
+
+	var auth []ssh.AuthMethod
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		// An agent that isn't listening is skipped, the private keys
+		// may be enough to authenticate.
+		if conn, err := net.Dial("unix", sock); err == nil {
+			conn.Close()
+			auth = append(auth, ssh.PublicKeysCallback(agentSigners(sock)))
+		}
+	}
+
+	var signers []ssh.Signer
+	for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+		key, err := ioutil.ReadFile(filepath.Join(sshDir, name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			if _, ok := err.(*ssh.PassphraseMissingError); ok {
+				// Encrypted keys can only be used through the agent.
+				continue
+			}
+			return nil, fmt.Errorf("unable to parse the ssh key %s: %v", name, err)
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) > 0 {
+		auth = append(auth, ssh.PublicKeys(signers...))
+	}
+	if len(auth) == 0 {
+		return nil, fmt.Errorf("unable to find an ssh agent or private keys to authenticate with")
+	}
+
+	return &ssh.ClientConfig{
+		User:            user,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+	}, nil
+}
+
+// agentSigners returns the function listing the keys of the ssh agent.
+// The agent is connected to for each operation, so that no connection
+// to it is left open once the ssh connections are authenticated.
+func agentSigners(sock string) func() ([]ssh.Signer, error) {
+	return func() ([]ssh.Signer, error) {
+		conn, err := net.Dial("unix", sock)
+		if err != nil {
+			// The agent stopped, the other methods may authenticate.
+			return nil, nil
+		}
+		defer conn.Close()
+
+		keys, err := agent.NewClient(conn).List()
+		if err != nil {
+			return nil, err
+		}
+		signers := make([]ssh.Signer, 0, len(keys))
+		for _, key := range keys {
+			signers = append(signers, agentSigner{sock: sock, key: key})
+		}
+		return signers, nil
+	}
+}
+
+// agentSigner signs with a key of the ssh agent.
+type agentSigner struct {
+	sock string
+	key  ssh.PublicKey
+}
+
+func (s agentSigner) PublicKey() ssh.PublicKey {
+	return s.key
+}
+
+func (s agentSigner) Sign(rand io.Reader, data []byte) (*ssh.Signature, error) {
+	return s.SignWithAlgorithm(rand, data, "")
+}
+
+func (s agentSigner) SignWithAlgorithm(rand io.Reader, data []byte, algorithm string) (*ssh.Signature, error) {
+	conn, err := net.Dial("unix", s.sock)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to the ssh agent: %v", err)
+	}
+	defer conn.Close()
+
+	signers, err := agent.NewClient(conn).Signers()
+	if err != nil {
+		return nil, err
+	}
+	for _, signer := range signers {
+		if !bytes.Equal(signer.PublicKey().Marshal(), s.key.Marshal()) {
+			continue
+		}
+		if algorithmSigner, ok := signer.(ssh.AlgorithmSigner); ok {
+			return algorithmSigner.SignWithAlgorithm(rand, data, algorithm)
+		}
+		return signer.Sign(rand, data)
+	}
+	return nil, fmt.Errorf("the key %s was removed from the ssh agent", ssh.FingerprintSHA256(s.key))
+}
+
+func homeDir() string {
+	if home := os.Getenv("HOME"); home != "" {
+		return home
+	}
+	return os.Getenv("USERPROFILE")
+}
+
+// sshTunnel opens connections to a remote unix socket over a single ssh
+// connection, reconnecting to the remote host when it's closed.
+type sshTunnel struct {
+	addr   string
+	socket string
+	config *ssh.ClientConfig
+
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+// Dial opens a new connection to the remote socket.
+func (t *sshTunnel) Dial() (net.Conn, error) {
+	client, err := t.sshClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.Dial("unix", t.socket)
+}
+
+// sshClient returns the ssh connection to the remote host,
+// connecting to it if it isn't connected yet.
+func (t *sshTunnel) sshClient() (*ssh.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.client != nil {
+		return t.client, nil
+	}
+
+	conn, err := net.DialTimeout("tcp", t.addr, t.config.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	// The timeout bounds the ssh handshake too, an unresponsive
+	// host would block the requests waiting for the connection.
+	conn.SetDeadline(time.Now().Add(t.config.Timeout))
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, t.addr, t.config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	client := ssh.NewClient(sshConn, chans, reqs)
+	t.client = client
+
+	go func() {
+		client.Wait()
+		t.mu.Lock()
+		if t.client == client {
+			t.client = nil
+		}
+		t.mu.Unlock()
+	}()
+	return client, nil
+}
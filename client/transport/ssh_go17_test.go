@@ -0,0 +1,52 @@
+// +build go1.7
+
+package transport
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHTransportWithHTTPClientDialContext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ssh-transport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newSSHServer(t, dir, signer.PublicKey(), http.HandlerFunc(pingHandler))
+	defer server.Close()
+	defer setupSSHHome(t, dir, server, clientKey)()
+
+	// DialContext takes precedence over Dial, it's cleared to use the tunnel.
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(_ context.Context, network, addr string) (net.Conn, error) {
+				return nil, fmt.Errorf("unexpected dial to %s %s", network, addr)
+			},
+		},
+	}
+	client, err := NewTransportWithHTTP("ssh", "docker@"+server.addr+server.socket, httpClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSSHPing(t, client)
+}
@@ -0,0 +1,470 @@
+package transport
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// sshServer is an in-process ssh server that forwards the connections
+// to a docker socket served by an http handler.
+type sshServer struct {
+	addr     string
+	socket   string
+	hostKey  ssh.Signer
+	listener net.Listener
+	http     net.Listener
+}
+
+func (s *sshServer) Close() {
+	s.listener.Close()
+	s.http.Close()
+}
+
+func newSSHServer(t *testing.T, dir string, authorized ssh.PublicKey, handler http.Handler) *sshServer {
+	socket := filepath.Join(dir, "docker.sock")
+	httpListener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go http.Serve(httpListener, handler)
+
+	hostKey := newSigner(t)
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if meta.User() == "docker" && string(key.Marshal()) == string(authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unauthorized key for %s", meta.User())
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		httpListener.Close()
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSSH(conn, config)
+		}
+	}()
+
+	return &sshServer{
+		addr:     listener.Addr().String(),
+		socket:   socket,
+		hostKey:  hostKey,
+		listener: listener,
+		http:     httpListener,
+	}
+}
+
+func serveSSH(conn net.Conn, config *ssh.ServerConfig) {
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "direct-streamlocal@openssh.com" {
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		var msg struct {
+			SocketPath string
+			Reserved0  string
+			Reserved1  uint32
+		}
+		if err := ssh.Unmarshal(newChannel.ExtraData(), &msg); err != nil {
+			newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		local, err := net.Dial("unix", msg.SocketPath)
+		if err != nil {
+			newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			local.Close()
+			continue
+		}
+		go ssh.DiscardRequests(channelRequests)
+		go func() {
+			io.Copy(channel, local)
+			channel.CloseWrite()
+		}()
+		go func() {
+			io.Copy(local, channel)
+			local.(*net.UnixConn).CloseWrite()
+		}()
+	}
+}
+
+func newSigner(t *testing.T) ssh.Signer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+// setupSSHHome creates a home directory with a private key and the known hosts
+// for the server, and points HOME to it. It returns a function to restore HOME.
+func setupSSHHome(t *testing.T, dir string, server *sshServer, clientKey *ecdsa.PrivateKey) func() {
+	sshDir := filepath.Join(dir, "home", ".ssh")
+	if err := os.MkdirAll(sshDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if server != nil {
+		line := knownhosts.Line([]string{knownhosts.Normalize(server.addr)}, server.hostKey.PublicKey())
+		if err := ioutil.WriteFile(filepath.Join(sshDir, "known_hosts"), []byte(line+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if clientKey != nil {
+		der, err := x509.MarshalECPrivateKey(clientKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+		if err := ioutil.WriteFile(filepath.Join(sshDir, "id_ecdsa"), key, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldHome, oldAgent := os.Getenv("HOME"), os.Getenv("SSH_AUTH_SOCK")
+	os.Setenv("HOME", filepath.Join(dir, "home"))
+	os.Setenv("SSH_AUTH_SOCK", "")
+	return func() {
+		os.Setenv("HOME", oldHome)
+		os.Setenv("SSH_AUTH_SOCK", oldAgent)
+	}
+}
+
+func pingHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/_ping" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	fmt.Fprint(w, "OK")
+}
+
+func testSSHPing(t *testing.T, client Client) {
+	req, err := http.NewRequest("GET", client.Scheme()+"://docker/_ping", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != "OK" {
+		t.Fatalf("expected a successful ping, got %d %q", resp.StatusCode, string(body))
+	}
+}
+
+func TestParseSSHAddr(t *testing.T) {
+	oldUser := os.Getenv("USER")
+	os.Setenv("USER", "current")
+	defer os.Setenv("USER", oldUser)
+
+	cases := []struct {
+		addr          string
+		expectedUser  string
+		expectedHost  string
+		expectedPath  string
+		expectedError string
+	}{
+		{addr: "docker@example.com", expectedUser: "docker", expectedHost: "example.com:22", expectedPath: DefaultSSHSocket},
+		{addr: "example.com:2222", expectedUser: "current", expectedHost: "example.com:2222", expectedPath: DefaultSSHSocket},
+		{addr: "docker@[::1]", expectedUser: "docker", expectedHost: "[::1]:22", expectedPath: DefaultSSHSocket},
+		{addr: "docker@example.com/run/docker.sock", expectedUser: "docker", expectedHost: "example.com:22", expectedPath: "/run/docker.sock"},
+		{addr: "docker:secret@example.com", expectedError: "passwords are not supported"},
+		{addr: "docker@example.com?socket=foo", expectedError: "unexpected query or fragment"},
+		{addr: "/var/run/docker.sock", expectedError: "missing host"},
+	}
+	for _, c := range cases {
+		user, host, path, err := ParseSSHAddr(c.addr)
+		if c.expectedError != "" {
+			if err == nil || !strings.Contains(err.Error(), c.expectedError) {
+				t.Fatalf("expected an error containing %q for %s, got %v", c.expectedError, c.addr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user != c.expectedUser || host != c.expectedHost || path != c.expectedPath {
+			t.Fatalf("expected %s, %s and %s for %s, got %s, %s and %s", c.expectedUser, c.expectedHost, c.expectedPath, c.addr, user, host, path)
+		}
+	}
+}
+
+func TestSSHTransportWithKeyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ssh-transport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newSSHServer(t, dir, signer.PublicKey(), http.HandlerFunc(pingHandler))
+	defer server.Close()
+	defer setupSSHHome(t, dir, server, clientKey)()
+
+	client, err := NewTransportWithHTTP("ssh", "docker@"+server.addr+server.socket, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Both requests share the same ssh connection.
+	testSSHPing(t, client)
+	testSSHPing(t, client)
+
+	// Hijacked requests dial their own connections.
+	conn, err := client.(Dialer).Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fmt.Fprint(conn, "GET /_ping HTTP/1.1\r\nHost: docker\r\nConnection: close\r\n\r\n")
+	content, err := ioutil.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), "HTTP/1.1 200 OK") || !strings.HasSuffix(string(content), "OK") {
+		t.Fatalf("expected a successful ping over the dialed connection, got %q", string(content))
+	}
+}
+
+func TestSSHTransportWithHTTPClient(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ssh-transport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newSSHServer(t, dir, signer.PublicKey(), http.HandlerFunc(pingHandler))
+	defer server.Close()
+	defer setupSSHHome(t, dir, server, clientKey)()
+
+	// The dialer of the transport is replaced by the tunnel.
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			Dial: func(network, addr string) (net.Conn, error) {
+				return nil, fmt.Errorf("unexpected dial to %s %s", network, addr)
+			},
+		},
+	}
+	client, err := NewTransportWithHTTP("ssh", "docker@"+server.addr+server.socket, httpClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSSHPing(t, client)
+}
+
+func TestSSHTransportWithAgent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ssh-transport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newSSHServer(t, dir, signer.PublicKey(), http.HandlerFunc(pingHandler))
+	defer server.Close()
+	defer setupSSHHome(t, dir, server, nil)()
+
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: clientKey}); err != nil {
+		t.Fatal(err)
+	}
+	agentListener, err := net.Listen("unix", filepath.Join(dir, "agent.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer agentListener.Close()
+	var agentConns int32
+	go func() {
+		for {
+			conn, err := agentListener.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&agentConns, 1)
+			go func() {
+				agent.ServeAgent(keyring, conn)
+				atomic.AddInt32(&agentConns, -1)
+			}()
+		}
+	}()
+	os.Setenv("SSH_AUTH_SOCK", agentListener.Addr().String())
+
+	client, err := NewTransportWithHTTP("ssh", "docker@"+server.addr+server.socket, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSSHPing(t, client)
+
+	// The connections to the agent are closed once authenticated.
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&agentConns) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the connections to the agent to be closed, %d are open", atomic.LoadInt32(&agentConns))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSSHTransportWithStaleAgent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ssh-transport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newSSHServer(t, dir, signer.PublicKey(), http.HandlerFunc(pingHandler))
+	defer server.Close()
+	defer setupSSHHome(t, dir, server, clientKey)()
+	// No agent listens on the socket.
+	os.Setenv("SSH_AUTH_SOCK", filepath.Join(dir, "stale-agent.sock"))
+
+	client, err := NewTransportWithHTTP("ssh", "docker@"+server.addr+server.socket, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSSHPing(t, client)
+}
+
+func TestSSHTransportUnknownHost(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ssh-transport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newSSHServer(t, dir, signer.PublicKey(), http.HandlerFunc(pingHandler))
+	defer server.Close()
+	// The server isn't in the known hosts.
+	defer setupSSHHome(t, dir, &sshServer{addr: "127.0.0.1:1", hostKey: newSigner(t)}, clientKey)()
+
+	client, err := NewTransportWithHTTP("ssh", "docker@"+server.addr+server.socket, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.(Dialer).Dial()
+	if err == nil || !strings.Contains(err.Error(), "key is unknown") {
+		t.Fatalf("expected an unknown host key error, got %v", err)
+	}
+}
+
+func TestSSHTransportTimeout(t *testing.T) {
+	// The host accepts the connections but never answers the handshake.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	config := &ssh.ClientConfig{
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         100 * time.Millisecond,
+	}
+	client, err := NewSSHTransport("docker@"+listener.Addr().String(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errC := make(chan error, 1)
+	go func() {
+		_, err := client.(Dialer).Dial()
+		errC <- err
+	}()
+	select {
+	case err := <-errC:
+		if err == nil {
+			t.Fatal("expected the ssh handshake to time out")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the ssh handshake to time out, it's still waiting")
+	}
+}
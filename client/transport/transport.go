@@ -17,8 +17,13 @@ type apiTransport struct {
 
 // NewTransportWithHTTP creates a new transport based on the provided proto, address and http client.
 // It uses Docker's default http transport configuration if the client is nil.
-// It does not modify the client's transport if it's not nil.
+// It does not modify the client's transport if it's not nil, unless the
+// protocol is ssh, where it sets the function to dial connections with.
 func NewTransportWithHTTP(proto, addr string, client *http.Client) (Client, error) {
+	if proto == "ssh" {
+		return newSSHTransport(addr, nil, client)
+	}
+
 	var transport *http.Transport
 
 	if client != nil {
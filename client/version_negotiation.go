@@ -0,0 +1,107 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/versions"
+	"golang.org/x/net/context"
+)
+
+// EnableAPIVersionNegotiation makes the client negotiate the API version
+// with the daemon before sending its first request.
+// The version configured in the client is the highest version it negotiates,
+// an empty version uses the version of the daemon.
+// Use UpdateClientVersion to pin a version and disable the negotiation.
+func (cli *Client) EnableAPIVersionNegotiation() {
+	cli.negotiateMu.Lock()
+	defer cli.negotiateMu.Unlock()
+
+	cli.negotiateVersion = true
+	cli.negotiated = false
+}
+
+// NegotiateAPIVersion asks the daemon for the API versions it supports
+// and updates the client to use the highest version both of them understand.
+// It returns an error if the daemon doesn't support the version of the client
+// or any older version.
+func (cli *Client) NegotiateAPIVersion(ctx context.Context) error {
+	cli.negotiateMu.Lock()
+	defer cli.negotiateMu.Unlock()
+
+	return cli.negotiateAPIVersion(ctx)
+}
+
+// negotiateAPIVersionOnce negotiates the API version if the negotiation
+// is enabled and it didn't succeed yet.
+func (cli *Client) negotiateAPIVersionOnce(ctx context.Context) error {
+	cli.negotiateMu.Lock()
+	defer cli.negotiateMu.Unlock()
+
+	if !cli.negotiateVersion || cli.negotiated {
+		return nil
+	}
+	return cli.negotiateAPIVersion(ctx)
+}
+
+func (cli *Client) negotiateAPIVersion(ctx context.Context) error {
+	serverVersion, minVersion, err := cli.serverAPIVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	version := cli.version
+	if version == "" || versions.LessThan(serverVersion, version) {
+		version = serverVersion
+	}
+	if minVersion != "" && versions.LessThan(version, minVersion) {
+		return fmt.Errorf("client version %s is too old. Minimum supported API version is %s, please upgrade your client to a newer version", version, minVersion)
+	}
+
+	cli.setVersion(version)
+	cli.negotiated = true
+	return nil
+}
+
+// serverAPIVersions returns the API version of the daemon, and the minimum
+// version it supports when the client needs to know it.
+func (cli *Client) serverAPIVersions(ctx context.Context) (string, string, error) {
+	ping, err := cli.Ping(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	version := ping.APIVersion
+	if version != "" && (cli.version == "" || !versions.LessThan(cli.version, version)) {
+		// The client talks the version of the daemon, or a newer one.
+		return version, "", nil
+	}
+
+	// Older daemons don't send their version when they're pinged,
+	// and only /version tells the minimum version a daemon supports.
+	resp, err := cli.getUnversioned(ctx, "/version")
+	if err != nil {
+		return "", "", err
+	}
+	defer ensureReaderClosed(resp)
+
+	var server types.Version
+	if err := json.NewDecoder(resp.body).Decode(&server); err != nil {
+		return "", "", err
+	}
+	if server.APIVersion == "" {
+		return "", "", fmt.Errorf("Error: the daemon didn't send its API version")
+	}
+	return server.APIVersion, server.MinAPIVersion, nil
+}
+
+// getUnversioned sends a GET request to a path of the API that
+// doesn't depend on its version, without negotiating it.
+func (cli *Client) getUnversioned(ctx context.Context, path string) (serverResponse, error) {
+	req, err := cli.newUnversionedRequest("GET", path, nil)
+	if err != nil {
+		return serverResponse{statusCode: -1}, err
+	}
+	return cli.sendHTTPRequest(ctx, req)
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+// negotiationMock replies to pings with the given API version header,
+// and to /version with the given body. It records the paths it's requested.
+func negotiationMock(pingVersion, versionBody string, paths *[]string) func(*http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		*paths = append(*paths, req.URL.Path)
+		header := http.Header{}
+		body := "{}"
+		switch req.URL.Path {
+		case "/_ping":
+			if pingVersion != "" {
+				header.Set("API-Version", pingVersion)
+			}
+			body = "OK"
+		case "/version":
+			body = versionBody
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+		}, nil
+	}
+}
+
+func TestAPIVersionNegotiation(t *testing.T) {
+	cases := []struct {
+		clientVersion   string
+		pingVersion     string
+		versionBody     string
+		expectedVersion string
+		expectedPaths   string
+	}{
+		{
+			clientVersion:   "1.24",
+			pingVersion:     "1.22",
+			expectedVersion: "1.22",
+			expectedPaths:   "/_ping,/v1.22/info",
+		},
+		{
+			clientVersion:   "1.22",
+			pingVersion:     "1.22",
+			expectedVersion: "1.22",
+			expectedPaths:   "/_ping,/v1.22/info",
+		},
+		{
+			clientVersion:   "",
+			pingVersion:     "1.25",
+			expectedVersion: "1.25",
+			expectedPaths:   "/_ping,/v1.25/info",
+		},
+		{
+			clientVersion:   "1.24",
+			versionBody:     `{"ApiVersion":"1.21"}`,
+			expectedVersion: "1.21",
+			expectedPaths:   "/_ping,/version,/v1.21/info",
+		},
+		{
+			clientVersion:   "1.24",
+			pingVersion:     "1.26",
+			versionBody:     `{"ApiVersion":"1.26","MinAPIVersion":"1.12"}`,
+			expectedVersion: "1.24",
+			expectedPaths:   "/_ping,/version,/v1.24/info",
+		},
+	}
+	for _, c := range cases {
+		var paths []string
+		client := &Client{
+			transport: newMockClient(nil, negotiationMock(c.pingVersion, c.versionBody, &paths)),
+			version:   c.clientVersion,
+		}
+		client.EnableAPIVersionNegotiation()
+
+		for i := 0; i < 2; i++ {
+			if _, err := client.Info(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if version := client.ClientVersion(); version != c.expectedVersion {
+			t.Fatalf("expected version %s, got %s", c.expectedVersion, version)
+		}
+		// The second request doesn't negotiate the version again.
+		expectedPaths := c.expectedPaths + fmt.Sprintf(",/v%s/info", c.expectedVersion)
+		if actual := strings.Join(paths, ","); actual != expectedPaths {
+			t.Fatalf("expected requests to %s, got %s", expectedPaths, actual)
+		}
+	}
+}
+
+func TestAPIVersionNegotiationConcurrentRequests(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	mock := negotiationMock("1.22", "", &paths)
+	client := &Client{
+		transport: newMockClient(nil, func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return mock(req)
+		}),
+		version: "1.24",
+	}
+	client.EnableAPIVersionNegotiation()
+
+	// The requests read the version while it's negotiated and updated.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Info(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	client.UpdateClientVersion("1.23")
+	wg.Wait()
+
+	for _, path := range paths {
+		if path != "/_ping" && path != "/v1.22/info" && path != "/v1.23/info" {
+			t.Fatalf("unexpected request to %s", path)
+		}
+	}
+}
+
+func TestAPIVersionNegotiationMinVersion(t *testing.T) {
+	var paths []string
+	client := &Client{
+		transport: newMockClient(nil, negotiationMock("1.26", `{"ApiVersion":"1.26","MinAPIVersion":"1.24"}`, &paths)),
+		version:   "1.22",
+	}
+	client.EnableAPIVersionNegotiation()
+
+	_, err := client.Info(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "Minimum supported API version is 1.24") {
+		t.Fatalf("expected a minimum version error, got %v", err)
+	}
+	if client.ClientVersion() != "1.22" {
+		t.Fatalf("expected the version to stay unchanged, got %s", client.ClientVersion())
+	}
+}
+
+func TestAPIVersionNegotiationError(t *testing.T) {
+	client := &Client{
+		transport: newMockClient(nil, errorMock(http.StatusInternalServerError, "Server error")),
+		version:   "1.24",
+	}
+	if err := client.NegotiateAPIVersion(context.Background()); err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+}
+
+func TestAPIVersionPinned(t *testing.T) {
+	var paths []string
+	client := &Client{
+		transport: newMockClient(nil, negotiationMock("1.22", "", &paths)),
+		version:   "1.24",
+	}
+	client.EnableAPIVersionNegotiation()
+	client.UpdateClientVersion("1.23")
+
+	if _, err := client.Info(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if actual := strings.Join(paths, ","); actual != "/v1.23/info" {
+		t.Fatalf("expected the pinned version to be used without negotiation, got requests to %s", actual)
+	}
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
+	"golang.org/x/net/context"
+)
+
+// VolumesPrune removes the volumes that aren't used by any container, and
+// reports the space reclaimed. The "label" and "label!" filters only prune
+// the volumes with, or without, the given labels.
+//
+// The volumes are removed one by one when the daemon doesn't support the
+// prune endpoint or the filters. The space reclaimed isn't reported then,
+// because the daemon doesn't report the size of the volumes.
+func (cli *Client) VolumesPrune(ctx context.Context, pruneFilters filters.Args) (types.VolumesPruneReport, error) {
+	var report types.VolumesPruneReport
+	if ok, err := cli.prune(ctx, "/volumes/prune", pruneFilters, &report); ok || err != nil {
+		return report, err
+	}
+	return cli.pruneVolumes(ctx, pruneFilters)
+}
+
+// pruneVolumes prunes the volumes on the client side.
+func (cli *Client) pruneVolumes(ctx context.Context, pruneFilters filters.Args) (types.VolumesPruneReport, error) {
+	var report types.VolumesPruneReport
+	f, err := newPruneFilter(pruneFilters, map[string]bool{"label": true, "label!": true})
+	if err != nil {
+		return report, err
+	}
+
+	danglingFilters := filters.NewArgs()
+	danglingFilters.Add("dangling", "true")
+	volumes, err := cli.VolumeList(ctx, danglingFilters)
+	if err != nil {
+		return report, err
+	}
+	for _, v := range volumes.Volumes {
+		if !f.match(0, v.Labels) {
+			continue
+		}
+		if err := cli.VolumeRemove(ctx, v.Name, false); err != nil {
+			if isPruneSkipped(err) {
+				continue
+			}
+			return report, err
+		}
+		report.VolumesDeleted = append(report.VolumesDeleted, v.Name)
+	}
+	return report, nil
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
+	"golang.org/x/net/context"
+)
+
+func TestVolumesPruneError(t *testing.T) {
+	client := &Client{
+		transport: newMockClient(nil, errorMock(http.StatusInternalServerError, "Server error")),
+		version:   "1.25",
+	}
+
+	_, err := client.VolumesPrune(context.Background(), filters.NewArgs())
+	if err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+}
+
+func TestVolumesPrune(t *testing.T) {
+	var requests []string
+	client := &Client{
+		transport: newMockClient(nil, pruneMock(map[string]interface{}{
+			"POST /v1.25/volumes/prune": types.VolumesPruneReport{
+				VolumesDeleted: []string{"volume1"},
+				SpaceReclaimed: 1024,
+			},
+		}, &requests)),
+		version: "1.25",
+	}
+
+	report, err := client.VolumesPrune(context.Background(), filters.NewArgs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report.VolumesDeleted, []string{"volume1"}) || report.SpaceReclaimed != 1024 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
+
+func TestVolumesPruneFallback(t *testing.T) {
+	pruneFilters := filters.NewArgs()
+	pruneFilters.Add("label!", "keep")
+
+	var (
+		requests   []string
+		filterJSON string
+	)
+	mock := pruneMock(map[string]interface{}{
+		"GET /v1.24/volumes": types.VolumesListResponse{
+			Volumes: []*types.Volume{
+				{Name: "volume1"},
+				{Name: "kept", Labels: map[string]string{"keep": ""}},
+				{Name: "in_use"},
+			},
+		},
+		"DELETE /v1.24/volumes/volume1": "",
+		"DELETE /v1.24/volumes/in_use":  http.StatusConflict,
+	}, &requests)
+	client := &Client{
+		transport: newMockClient(nil, func(req *http.Request) (*http.Response, error) {
+			if req.Method == "GET" {
+				filterJSON = req.URL.Query().Get("filters")
+			}
+			return mock(req)
+		}),
+		version: "1.24",
+	}
+
+	report, err := client.VolumesPrune(context.Background(), pruneFilters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filterJSON != `{"dangling":{"true":true}}` {
+		t.Fatalf("expected the dangling volumes to be listed, got filters %s", filterJSON)
+	}
+	expectedRequests := []string{
+		"GET /v1.24/volumes",
+		"DELETE /v1.24/volumes/volume1",
+		"DELETE /v1.24/volumes/in_use",
+	}
+	if !reflect.DeepEqual(requests, expectedRequests) {
+		t.Fatalf("expected requests %v, got %v", expectedRequests, requests)
+	}
+	if !reflect.DeepEqual(report.VolumesDeleted, []string{"volume1"}) || report.SpaceReclaimed != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
@@ -0,0 +1,227 @@
+// Package jsonmessage decodes the streams of JSON messages the daemon
+// sends while pulling, pushing, importing, loading and building images.
+//
+// The daemon answers those requests with a 200 status code before the
+// operation finishes, failures are reported in the stream itself.
+package jsonmessage
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/docker/engine-api/types"
+)
+
+// JSONError wraps a concrete Code and Message, `Code` is
+// an integer error code, `Message` is the error message.
+type JSONError struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Error returns the message of the error.
+func (e *JSONError) Error() string {
+	return e.Message
+}
+
+// JSONProgress describes a progress. Current is the amount of work done,
+// Total the amount of work to do, and Start the unix time when the
+// work started.
+type JSONProgress struct {
+	Current int64 `json:"current,omitempty"`
+	Total   int64 `json:"total,omitempty"`
+	Start   int64 `json:"start,omitempty"`
+}
+
+// JSONMessage defines a message struct. It describes
+// the status of an operation, the progress of a layer
+// or the output of a build, and the errors reported.
+type JSONMessage struct {
+	Stream          string        `json:"stream,omitempty"`
+	Status          string        `json:"status,omitempty"`
+	Progress        *JSONProgress `json:"progressDetail,omitempty"`
+	ProgressMessage string        `json:"progress,omitempty"`
+	ID              string        `json:"id,omitempty"`
+	From            string        `json:"from,omitempty"`
+	Time            int64         `json:"time,omitempty"`
+	TimeNano        int64         `json:"timeNano,omitempty"`
+	Error           *JSONError    `json:"errorDetail,omitempty"`
+	// ErrorMessage is the legacy form of Error.
+	ErrorMessage string `json:"error,omitempty"`
+	// Aux contains out-of-band data, such as digests for push signing.
+	Aux *json.RawMessage `json:"aux,omitempty"`
+}
+
+// Err returns the error reported in the message, if any.
+func (m *JSONMessage) Err() error {
+	if m.Error != nil {
+		return m.Error
+	}
+	if m.ErrorMessage != "" {
+		return &JSONError{Message: m.ErrorMessage}
+	}
+	return nil
+}
+
+// Progress is the progress of all the layers in a stream.
+type Progress struct {
+	// Current and Total are the sum of the work done
+	// and to do for all the layers, in bytes.
+	Current int64
+	Total   int64
+	// Layers is the number of layers in the stream,
+	// Completed the number of layers that are done.
+	Layers    int
+	Completed int
+}
+
+// Result holds the out-of-band information collected from a stream.
+type Result struct {
+	// Aux contains all the aux payloads in the stream.
+	Aux []json.RawMessage
+	// Digest is the digest of the pulled or pushed image.
+	Digest string
+	// ImageID is the ID of the built image.
+	ImageID string
+}
+
+// Handlers are the functions called while a stream is decoded.
+// Any of them can be nil.
+type Handlers struct {
+	// Message is called with every message in the stream.
+	Message func(JSONMessage)
+	// Progress is called every time the progress of a layer changes.
+	Progress func(Progress)
+}
+
+// completedStatuses are the status of layers that don't have work left.
+var completedStatuses = map[string]bool{
+	"Already exists":       true,
+	"Pull complete":        true,
+	"Pushed":               true,
+	"Layer already exists": true,
+}
+
+// layerProgress tracks the progress of a layer.
+type layerProgress struct {
+	current  int64
+	total    int64
+	complete bool
+}
+
+// DecodeStream reads the messages in the stream until it ends.
+// It returns the first error reported in the stream, or the
+// error decoding it.
+func DecodeStream(in io.Reader, handlers Handlers) (Result, error) {
+	var (
+		result Result
+		dec    = json.NewDecoder(in)
+		layers = make(map[string]*layerProgress)
+		order  []string
+	)
+
+	for {
+		var m JSONMessage
+		if err := dec.Decode(&m); err != nil {
+			if err == io.EOF {
+				return result, nil
+			}
+			return result, err
+		}
+
+		if handlers.Message != nil {
+			handlers.Message(m)
+		}
+		if err := m.Err(); err != nil {
+			return result, err
+		}
+
+		if m.Aux != nil {
+			result.addAux(*m.Aux)
+			continue
+		}
+		if m.ID == "" {
+			// Pulls report the digest of the image in a status message,
+			// and daemons without aux messages report the built image ID
+			// in the build output.
+			if strings.HasPrefix(m.Status, "Digest: ") {
+				result.Digest = strings.TrimPrefix(m.Status, "Digest: ")
+			}
+			if strings.HasPrefix(m.Stream, "Successfully built ") {
+				result.ImageID = strings.TrimSpace(strings.TrimPrefix(m.Stream, "Successfully built "))
+			}
+			continue
+		}
+
+		layer, ok := layers[m.ID]
+		if !ok {
+			if m.Progress == nil && !isCompleted(m.Status) {
+				// Status messages for the image, not for a layer.
+				continue
+			}
+			layer = &layerProgress{}
+			layers[m.ID] = layer
+			order = append(order, m.ID)
+		}
+
+		switch {
+		case isCompleted(m.Status):
+			layer.complete = true
+			layer.current = layer.total
+		case m.Progress != nil && m.Progress.Total > 0:
+			layer.current = m.Progress.Current
+			layer.total = m.Progress.Total
+		default:
+			continue
+		}
+
+		if handlers.Progress != nil {
+			handlers.Progress(aggregateProgress(layers, order))
+		}
+	}
+}
+
+// isCompleted returns whether the status of a layer means it has no work left.
+func isCompleted(status string) bool {
+	if completedStatuses[status] {
+		return true
+	}
+	// Cross repository mounts include the source repository.
+	return strings.HasPrefix(status, "Mounted from ")
+}
+
+// aggregateProgress sums the progress of all the layers.
+func aggregateProgress(layers map[string]*layerProgress, order []string) Progress {
+	p := Progress{Layers: len(order)}
+	for _, id := range order {
+		layer := layers[id]
+		p.Current += layer.current
+		p.Total += layer.total
+		if layer.complete {
+			p.Completed++
+		}
+	}
+	return p
+}
+
+// addAux records an aux payload, extracting the
+// push digest and the built image ID from it.
+func (r *Result) addAux(aux json.RawMessage) {
+	r.Aux = append(r.Aux, aux)
+
+	var payload struct {
+		types.PushResult
+		types.BuildResult
+	}
+	if err := json.Unmarshal(aux, &payload); err != nil {
+		// Payloads of other operations are kept as they are.
+		return
+	}
+	if payload.Digest != "" {
+		r.Digest = payload.Digest
+	}
+	if payload.ID != "" {
+		r.ImageID = payload.ID
+	}
+}
@@ -0,0 +1,128 @@
+package jsonmessage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeStreamPull(t *testing.T) {
+	stream := `{"status":"Pulling from library/busybox","id":"latest"}
+{"status":"Pulling fs layer","progressDetail":{},"id":"a"}
+{"status":"Pulling fs layer","progressDetail":{},"id":"b"}
+{"status":"Downloading","progressDetail":{"current":50,"total":100},"progress":"[=>   ]","id":"a"}
+{"status":"Downloading","progressDetail":{"current":10,"total":200},"id":"b"}
+{"status":"Pull complete","progressDetail":{},"id":"a"}
+{"status":"Already exists","progressDetail":{},"id":"c"}
+{"status":"Digest: sha256:0123456789abcdef"}
+{"status":"Status: Downloaded newer image for busybox:latest"}
+`
+	var (
+		messages int
+		progress []Progress
+	)
+	result, err := DecodeStream(strings.NewReader(stream), Handlers{
+		Message: func(JSONMessage) {
+			messages++
+		},
+		Progress: func(p Progress) {
+			progress = append(progress, p)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if messages != 9 {
+		t.Fatalf("expected 9 messages, got %d", messages)
+	}
+	if result.Digest != "sha256:0123456789abcdef" {
+		t.Fatalf("expected the digest of the image, got %q", result.Digest)
+	}
+
+	expected := []Progress{
+		{Current: 50, Total: 100, Layers: 2},
+		{Current: 60, Total: 300, Layers: 2},
+		{Current: 110, Total: 300, Layers: 2, Completed: 1},
+		{Current: 110, Total: 300, Layers: 3, Completed: 2},
+	}
+	if len(progress) != len(expected) {
+		t.Fatalf("expected %d progress updates, got %v", len(expected), progress)
+	}
+	for i, p := range expected {
+		if progress[i] != p {
+			t.Fatalf("expected progress %+v, got %+v", p, progress[i])
+		}
+	}
+}
+
+func TestDecodeStreamError(t *testing.T) {
+	cases := []struct {
+		stream        string
+		expectedError string
+	}{
+		{
+			stream:        `{"status":"Pulling repository docker.io/library/foo"}` + "\n" + `{"errorDetail":{"code":1,"message":"image not found"},"error":"image not found"}`,
+			expectedError: "image not found",
+		},
+		{
+			stream:        `{"error":"legacy error"}`,
+			expectedError: "legacy error",
+		},
+		{
+			stream:        `{"status":`,
+			expectedError: "unexpected EOF",
+		},
+	}
+	for _, c := range cases {
+		_, err := DecodeStream(strings.NewReader(c.stream), Handlers{})
+		if err == nil || err.Error() != c.expectedError {
+			t.Fatalf("expected error %q, got %v", c.expectedError, err)
+		}
+	}
+
+	_, err := DecodeStream(strings.NewReader(cases[0].stream), Handlers{})
+	if jsonErr, ok := err.(*JSONError); !ok || jsonErr.Code != 1 {
+		t.Fatalf("expected a JSONError with code 1, got %v", err)
+	}
+}
+
+func TestDecodeStreamAux(t *testing.T) {
+	cases := []struct {
+		stream          string
+		expectedDigest  string
+		expectedImageID string
+	}{
+		{
+			stream: `{"status":"Pushed","progressDetail":{},"id":"a"}
+{"status":"latest: digest: sha256:abc size: 528"}
+{"progressDetail":{},"aux":{"Tag":"latest","Digest":"sha256:abc","Size":528}}
+`,
+			expectedDigest: "sha256:abc",
+		},
+		{
+			stream: `{"stream":"Step 1 : FROM busybox\n"}
+{"aux":{"ID":"sha256:def"}}
+{"stream":"Successfully built def\n"}
+`,
+			expectedImageID: "def",
+		},
+		{
+			stream: `{"stream":"Step 1 : FROM busybox\n"}
+{"aux":"something else"}
+{"stream":"Successfully built 0123\n"}
+`,
+			expectedImageID: "0123",
+		},
+	}
+	for _, c := range cases {
+		result, err := DecodeStream(strings.NewReader(c.stream), Handlers{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Aux) != 1 {
+			t.Fatalf("expected 1 aux payload, got %d", len(result.Aux))
+		}
+		if result.Digest != c.expectedDigest || result.ImageID != c.expectedImageID {
+			t.Fatalf("expected digest %q and image ID %q, got %+v", c.expectedDigest, c.expectedImageID, result)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Package stdcopy multiplexes and demultiplexes the standard streams
+// of containers that don't have a TTY attached.
+//
+// The daemon frames the output of logs, attach and exec requests with
+// an 8 bytes header: the first byte is the stream the frame belongs to,
+// the next three bytes are zero and the last four bytes are the big
+// endian size of the payload that follows.
+package stdcopy
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/docker/engine-api/types/container"
+)
+
+// StdType is the type of standard stream
+// a writer can multiplex to.
+type StdType byte
+
+const (
+	// Stdin represents standard input stream type.
+	Stdin StdType = iota
+	// Stdout represents standard output stream type.
+	Stdout
+	// Stderr represents standard error steam type.
+	Stderr
+	// Systemerr represents errors originating from the daemon
+	// while the stream was being written.
+	Systemerr
+
+	stdWriterPrefixLen = 8
+	stdWriterFdIndex   = 0
+	stdWriterSizeIndex = 4
+
+	startingBufLen = 32*1024 + stdWriterPrefixLen + 1
+)
+
+// maxFrameLen is the largest frame payload StdCopy accepts.
+// It protects from corrupted headers making it allocate huge buffers.
+const maxFrameLen = 1 << 30
+
+// ErrInvalidStdHeader is returned when the header of a frame
+// references an unknown stream.
+var ErrInvalidStdHeader = errors.New("Unrecognized input header")
+
+// systemError is returned when the daemon reports an error
+// in the Systemerr stream.
+type systemError struct {
+	message string
+}
+
+// Error returns a string representation of a systemError.
+func (e systemError) Error() string {
+	return fmt.Sprintf("Error from daemon in stream: %s", e.message)
+}
+
+// IsErrSystem returns true if the error was reported
+// by the daemon in the Systemerr stream.
+func IsErrSystem(err error) bool {
+	_, ok := err.(systemError)
+	return ok
+}
+
+// stdWriter is wrapper of io.Writer with extra customized info.
+type stdWriter struct {
+	io.Writer
+	prefix byte
+}
+
+// Write sends the buffer to the underlying writer.
+// It inserts the prefix header before the buffer,
+// so StdCopy knows where to multiplex the output.
+// It returns the number of bytes written from p.
+func (w *stdWriter) Write(p []byte) (int, error) {
+	if w == nil || w.Writer == nil {
+		return 0, errors.New("Writer not instantiated")
+	}
+	if p == nil {
+		return 0, nil
+	}
+
+	frame := make([]byte, stdWriterPrefixLen+len(p))
+	frame[stdWriterFdIndex] = w.prefix
+	binary.BigEndian.PutUint32(frame[stdWriterSizeIndex:], uint32(len(p)))
+	copy(frame[stdWriterPrefixLen:], p)
+
+	n, err := w.Writer.Write(frame)
+	n -= stdWriterPrefixLen
+	if n < 0 {
+		n = 0
+	}
+	return n, err
+}
+
+// NewStdWriter instantiates a new Writer.
+// Everything written to it will be encapsulated using a custom format,
+// and written to the underlying `w` stream.
+// This allows multiple write streams (e.g. stdout and stderr) to be muxed into a single connection.
+// `t` indicates the id of the stream to encapsulate.
+// It can be stdcopy.Stdin, stdcopy.Stdout, stdcopy.Stderr or stdcopy.Systemerr.
+func NewStdWriter(w io.Writer, t StdType) io.Writer {
+	return &stdWriter{
+		Writer: w,
+		prefix: byte(t),
+	}
+}
+
+// StdCopy is a modified version of io.Copy.
+//
+// StdCopy will demultiplex `src`, assuming that it contains two streams,
+// previously multiplexed together using a StdWriter instance.
+// As it reads from `src`, StdCopy will write to `dstout` and `dsterr`.
+//
+// StdCopy will read until it hits EOF on `src`. It will then return a nil error.
+// In other words: if `err` is non nil, it indicates a real underlying error.
+// Frames in the Systemerr stream stop the copy and are returned as an error.
+//
+// `written` will hold the total number of bytes written to `dstout` and `dsterr`.
+func StdCopy(dstout, dsterr io.Writer, src io.Reader) (written int64, err error) {
+	var (
+		buf       = make([]byte, startingBufLen)
+		bufLen    = len(buf)
+		nr, nw    int
+		er, ew    error
+		out       io.Writer
+		frameSize int
+	)
+
+	for {
+		// Make sure we have at least a full header
+		for nr < stdWriterPrefixLen {
+			var nr2 int
+			nr2, er = src.Read(buf[nr:])
+			nr += nr2
+			if er == io.EOF {
+				if nr < stdWriterPrefixLen {
+					return written, nil
+				}
+				break
+			}
+			if er != nil {
+				return 0, er
+			}
+		}
+
+		stream := StdType(buf[stdWriterFdIndex])
+		// Check the first byte to know where to write
+		switch stream {
+		case Stdin:
+			fallthrough
+		case Stdout:
+			// Write on stdout
+			out = dstout
+		case Stderr:
+			// Write on stderr
+			out = dsterr
+		case Systemerr:
+			// If we're on Systemerr, we won't write anywhere.
+			// NB: if this code changes later, make sure you don't try to write
+			// to outstream if Systemerr is the stream
+			out = nil
+		default:
+			return 0, ErrInvalidStdHeader
+		}
+
+		// Retrieve the size of the frame
+		frameSize = int(binary.BigEndian.Uint32(buf[stdWriterSizeIndex : stdWriterSizeIndex+4]))
+		if frameSize > maxFrameLen {
+			return 0, ErrInvalidStdHeader
+		}
+
+		// Check if the buffer is big enough to read the frame.
+		// Extend it if necessary.
+		if frameSize+stdWriterPrefixLen > bufLen {
+			buf = append(buf, make([]byte, frameSize+stdWriterPrefixLen-bufLen+1)...)
+			bufLen = len(buf)
+		}
+
+		// While the amount of bytes read is less than the size of the frame + header, we keep reading
+		for nr < frameSize+stdWriterPrefixLen {
+			var nr2 int
+			nr2, er = src.Read(buf[nr:])
+			nr += nr2
+			if er == io.EOF {
+				if nr < frameSize+stdWriterPrefixLen {
+					return written, nil
+				}
+				break
+			}
+			if er != nil {
+				return 0, er
+			}
+		}
+
+		// we might have an error from the source mixed up in our multiplexed
+		// stream. if we do, return it.
+		if stream == Systemerr {
+			return written, systemError{string(buf[stdWriterPrefixLen : frameSize+stdWriterPrefixLen])}
+		}
+
+		// Write the retrieved frame (without header)
+		nw, ew = out.Write(buf[stdWriterPrefixLen : frameSize+stdWriterPrefixLen])
+		if ew != nil {
+			return 0, ew
+		}
+		// If the frame has not been fully written: error
+		if nw != frameSize {
+			return 0, io.ErrShortWrite
+		}
+		written += int64(nw)
+
+		// Move the rest of the buffer to the beginning
+		copy(buf, buf[frameSize+stdWriterPrefixLen:])
+		// Move the index
+		nr -= frameSize + stdWriterPrefixLen
+	}
+}
+
+// Copy copies the output of a container into dstout and dsterr.
+// Streams from containers with a TTY attached are not multiplexed,
+// in that case the output is copied to dstout as is.
+func Copy(dstout, dsterr io.Writer, src io.Reader, tty bool) (written int64, err error) {
+	if tty {
+		return io.Copy(dstout, src)
+	}
+	return StdCopy(dstout, dsterr, src)
+}
+
+// IsMultiplexed returns whether the output of a container
+// created with the given configuration is multiplexed.
+func IsMultiplexed(config *container.Config) bool {
+	return config == nil || !config.Tty
+}
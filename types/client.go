@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"io"
 	"net"
+	"time"
 
 	"github.com/docker/engine-api/types/container"
 	"github.com/docker/engine-api/types/filters"
@@ -23,6 +24,7 @@ type ContainerAttachOptions struct {
 	Stdout     bool
 	Stderr     bool
 	DetachKeys string
+	Logs       bool
 }
 
 // ContainerCommitOptions holds parameters to commit changes into a container.
@@ -37,10 +39,45 @@ type ContainerCommitOptions struct {
 
 // ContainerExecInspect holds information returned by exec inspect.
 type ContainerExecInspect struct {
-	ExecID      string
-	ContainerID string
-	Running     bool
-	ExitCode    int
+	ExecID        string `json:"ID"`
+	ContainerID   string
+	Running       bool
+	ExitCode      int
+	Pid           int
+	ProcessConfig ExecProcessConfig
+	OpenStdin     bool
+	OpenStderr    bool
+	OpenStdout    bool
+	CanRemove     bool
+	DetachKeys    string
+}
+
+// ExecProcessConfig holds information about the process of an exec.
+type ExecProcessConfig struct {
+	Tty        bool     `json:"tty"`
+	Entrypoint string   `json:"entrypoint"`
+	Arguments  []string `json:"arguments"`
+	Privileged *bool    `json:"privileged,omitempty"`
+	User       string   `json:"user,omitempty"`
+}
+
+// ExecRunOptions holds parameters to run a command in a container.
+// The command reads its input from Stdin if it's not nil.
+type ExecRunOptions struct {
+	User       string
+	Privileged bool
+	Tty        bool
+	Env        []string
+	Cmd        []string
+	Stdin      io.Reader
+}
+
+// ExecRunResult holds the result of a command run in a container.
+type ExecRunResult struct {
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+	Duration time.Duration
 }
 
 // ContainerListOptions holds parameters to list containers with.
@@ -73,15 +110,74 @@ type ContainerRemoveOptions struct {
 	Force         bool
 }
 
+// ContainerRunOptions holds parameters to run containers.
+// The streams that are nil are not attached to the container.
+type ContainerRunOptions struct {
+	Name   string
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
 // ContainerStartOptions holds parameters to start containers.
 type ContainerStartOptions struct {
 	CheckpointID string
 }
 
+// WaitCondition is a state of a container to wait for.
+type WaitCondition string
+
+const (
+	// WaitConditionNotRunning waits until the container is not running.
+	WaitConditionNotRunning WaitCondition = "not-running"
+	// WaitConditionNextExit waits until the container exits,
+	// even if it's not running when the wait starts.
+	WaitConditionNextExit WaitCondition = "next-exit"
+	// WaitConditionRunning waits until the container is running.
+	WaitConditionRunning WaitCondition = "running"
+	// WaitConditionHealthy waits until the health check of the container passes.
+	WaitConditionHealthy WaitCondition = "healthy"
+	// WaitConditionRemoved waits until the container is removed.
+	WaitConditionRemoved WaitCondition = "removed"
+)
+
+// ContainerWaitOptions holds parameters to wait for a container.
+type ContainerWaitOptions struct {
+	// Condition is the state to wait for, WaitConditionNotRunning by default.
+	Condition WaitCondition
+	// MaxFailingStreak is the number of consecutive failed health checks
+	// to stop waiting for a healthy container after. Zero waits until the
+	// daemon considers the container unhealthy.
+	MaxFailingStreak int
+	// PollInterval is the time between the inspections of the container
+	// made in case the events of the daemon are missed, one second by default.
+	PollInterval time.Duration
+}
+
+// ContainerWaitResult holds the state of a container that met a wait condition.
+type ContainerWaitResult struct {
+	// State is the state of the container, nil if it was removed.
+	State     *ContainerState
+	ExitCode  int
+	OOMKilled bool
+	HealthLog []*HealthcheckResult
+}
+
 // CopyToContainerOptions holds information
 // about files to copy into a container
 type CopyToContainerOptions struct {
 	AllowOverwriteDirWithFile bool
+	CopyUIDGID                bool
+}
+
+// CopyPathOptions holds parameters to copy a path
+// between the host and a container.
+type CopyPathOptions struct {
+	// FollowLink copies the target of the source path
+	// if it's a symbolic link, instead of the link itself.
+	FollowLink bool
+	// CopyUIDGID preserves the uid and gid of the copied files.
+	CopyUIDGID bool
 }
 
 // EventsOptions hold parameters to filter events with.
@@ -91,6 +187,36 @@ type EventsOptions struct {
 	Filters filters.Args
 }
 
+// EventsOverflowPolicy defines what to do with decoded events
+// when the consumer doesn't keep up with the stream.
+type EventsOverflowPolicy int
+
+const (
+	// EventsOverflowBlock stops reading from the daemon until the consumer catches up.
+	EventsOverflowBlock EventsOverflowPolicy = iota
+	// EventsOverflowDropNewest discards events received while the buffer is full.
+	EventsOverflowDropNewest
+	// EventsOverflowDropOldest discards the oldest buffered event to make room for a new one.
+	EventsOverflowDropOldest
+)
+
+// EventsStreamOptions hold parameters to stream decoded events with.
+type EventsStreamOptions struct {
+	EventsOptions
+
+	// BufferSize is the number of decoded events to hold
+	// before applying the Overflow policy.
+	BufferSize int
+	// Overflow is the policy to apply when the buffer is full.
+	Overflow EventsOverflowPolicy
+	// MaxReconnects is the number of consecutive times to re-issue the
+	// request after the stream is dropped. Negative values disable reconnection,
+	// zero keeps reconnecting until the context is done.
+	MaxReconnects int
+	// ReconnectDelay is the time to wait before reconnecting.
+	ReconnectDelay time.Duration
+}
+
 // NetworkListOptions holds parameters to filter the list of networks with.
 type NetworkListOptions struct {
 	Filters filters.Args
@@ -264,6 +390,18 @@ type ServiceCreateOptions struct {
 	EncodedRegistryAuth string
 }
 
+// SecretCreateResponse contains the information returned to a client
+// on the creation of a new secret.
+type SecretCreateResponse struct {
+	// ID is the ID of the created secret.
+	ID string
+}
+
+// SecretListOptions holds parameters to list secrets with.
+type SecretListOptions struct {
+	Filter filters.Args
+}
+
 // ServiceCreateResponse contains the information returned to a client
 // on the  creation of a new service.
 type ServiceCreateResponse struct {
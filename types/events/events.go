@@ -1,5 +1,7 @@
 package events
 
+import "strconv"
+
 const (
 	// ContainerEventType is the event type that containers generate
 	ContainerEventType = "container"
@@ -40,3 +42,47 @@ type Message struct {
 	Time     int64 `json:"time,omitempty"`
 	TimeNano int64 `json:"timeNano,omitempty"`
 }
+
+// Attribute returns the value of the given attribute key,
+// and whether the actor defines it.
+func (a Actor) Attribute(key string) (string, bool) {
+	v, ok := a.Attributes[key]
+	return v, ok
+}
+
+// Name returns the name of the actor, like the container
+// or network name, if the event includes it.
+func (a Actor) Name() string {
+	return a.Attributes["name"]
+}
+
+// Image returns the image name of the actor.
+// Container events include the image the container was created from.
+func (a Actor) Image() string {
+	return a.Attributes["image"]
+}
+
+// Signal returns the signal sent to a container in a kill event.
+func (a Actor) Signal() string {
+	return a.Attributes["signal"]
+}
+
+// Container returns the container ID in network connect and
+// disconnect events, and in volume mount and unmount events.
+func (a Actor) Container() string {
+	return a.Attributes["container"]
+}
+
+// ExitCode returns the exit code of a container in a die event.
+// It returns false if the attribute is missing or it's not a number.
+func (a Actor) ExitCode() (int, bool) {
+	v, ok := a.Attributes["exitCode"]
+	if !ok {
+		return 0, false
+	}
+	code, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, false
+	}
+	return code, true
+}
@@ -7,6 +7,9 @@ import (
 // Parse parses the given references and returns the repository and
 // tag (if present) from it. If there is an error during parsing, it will
 // return an error.
+//
+// Deprecated: the digest of a reference is returned as its tag,
+// use ParseNormalizedNamed instead.
 func Parse(ref string) (string, string, error) {
 	distributionRef, err := distreference.ParseNamed(ref)
 	if err != nil {
@@ -20,6 +23,9 @@ func Parse(ref string) (string, string, error) {
 // GetTagFromNamedRef returns a tag from the specified reference.
 // This function is necessary as long as the docker "server" api makes the distinction between repository
 // and tags.
+//
+// Deprecated: the digest of a reference is returned as its tag,
+// use ParseNormalizedNamed instead.
 func GetTagFromNamedRef(ref distreference.Named) string {
 	var tag string
 	switch x := ref.(type) {
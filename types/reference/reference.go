@@ -0,0 +1,188 @@
+package reference
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	distreference "github.com/docker/distribution/reference"
+)
+
+const (
+	// DefaultDomain is the domain of the registry of the
+	// references that don't specify one.
+	DefaultDomain = "docker.io"
+	// DefaultTag is the tag of the references that
+	// specify neither a tag nor a digest.
+	DefaultTag = "latest"
+
+	legacyDefaultDomain = "index.docker.io"
+	officialRepoPrefix  = "library/"
+)
+
+var (
+	// anchoredIdentifierRegexp matches the IDs of images, which
+	// can't be used as the name of a repository.
+	anchoredIdentifierRegexp = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+	digestRegexp = regexp.MustCompile(`^[a-z0-9]+:[a-f0-9]+$`)
+
+	// digestSizes are the lengths of the hex-encoded
+	// digests of the supported algorithms.
+	digestSizes = map[string]int{
+		"sha256": 64,
+		"sha384": 96,
+		"sha512": 128,
+	}
+)
+
+// Named is a normalized image reference. It has the domain of the
+// registry, the path of the repository in the registry, and an
+// optional tag and digest.
+type Named struct {
+	domain string
+	path   string
+	tag    string
+	digest string
+}
+
+// ParseNormalizedNamed parses an image reference, which can be familiar
+// like "ubuntu", and normalizes it, like "docker.io/library/ubuntu".
+// The tag and the digest of the reference are kept as is.
+func ParseNormalizedNamed(ref string) (Named, error) {
+	if ref == "" {
+		return Named{}, distreference.ErrNameEmpty
+	}
+	if anchoredIdentifierRegexp.MatchString(ref) {
+		return Named{}, fmt.Errorf("invalid repository name (%s), cannot specify 64-byte hexadecimal strings", ref)
+	}
+
+	domain, remainder := splitDockerDomain(ref)
+	parsed, err := distreference.Parse(domain + "/" + remainder)
+	if err != nil {
+		return Named{}, err
+	}
+	distributionRef, ok := parsed.(distreference.Named)
+	if !ok {
+		return Named{}, fmt.Errorf("invalid reference format: %s has no repository name", ref)
+	}
+
+	named := Named{
+		domain: domain,
+		path:   strings.TrimPrefix(distributionRef.Name(), domain+"/"),
+	}
+	if tagged, ok := distributionRef.(distreference.Tagged); ok {
+		named.tag = tagged.Tag()
+	}
+	if digested, ok := distributionRef.(distreference.Digested); ok {
+		named.digest = digested.Digest().String()
+		if err := ValidateDigest(named.digest); err != nil {
+			return Named{}, err
+		}
+	}
+	return named, nil
+}
+
+// splitDockerDomain splits the domain of the registry from the rest of
+// the reference, if the first component looks like a host name.
+func splitDockerDomain(ref string) (domain, remainder string) {
+	i := strings.IndexRune(ref, '/')
+	if i == -1 || (!strings.ContainsAny(ref[:i], ".:") && ref[:i] != "localhost") {
+		domain, remainder = DefaultDomain, ref
+	} else {
+		domain, remainder = ref[:i], ref[i+1:]
+	}
+	if domain == legacyDefaultDomain {
+		domain = DefaultDomain
+	}
+	if domain == DefaultDomain && !strings.ContainsRune(remainder, '/') {
+		remainder = officialRepoPrefix + remainder
+	}
+	return domain, remainder
+}
+
+// ValidateDigest returns an error if the digest isn't made of a supported
+// algorithm and the hex-encoded hash of the right length, like "sha256:" followed
+// by 64 hexadecimal characters.
+func ValidateDigest(digest string) error {
+	if !digestRegexp.MatchString(digest) {
+		return fmt.Errorf("invalid digest format: %s", digest)
+	}
+	i := strings.IndexRune(digest, ':')
+	size, ok := digestSizes[digest[:i]]
+	if !ok {
+		return fmt.Errorf("unsupported digest algorithm: %s", digest)
+	}
+	if len(digest[i+1:]) != size {
+		return fmt.Errorf("invalid digest length: %s", digest)
+	}
+	return nil
+}
+
+// Domain returns the domain of the registry, like "docker.io".
+func (r Named) Domain() string {
+	return r.domain
+}
+
+// Path returns the path of the repository in the registry, like "library/ubuntu".
+func (r Named) Path() string {
+	return r.path
+}
+
+// Name returns the normalized name of the repository, like "docker.io/library/ubuntu".
+func (r Named) Name() string {
+	return r.domain + "/" + r.path
+}
+
+// Tag returns the tag of the reference, if any.
+func (r Named) Tag() string {
+	return r.tag
+}
+
+// Digest returns the digest of the reference, if any.
+func (r Named) Digest() string {
+	return r.digest
+}
+
+// String returns the normalized reference, like "docker.io/library/ubuntu:latest".
+func (r Named) String() string {
+	return r.Name() + r.suffix()
+}
+
+// FamiliarName returns the name of the repository the way it's displayed,
+// without the default domain nor the prefix of the official repositories,
+// like "ubuntu".
+func (r Named) FamiliarName() string {
+	if r.domain != DefaultDomain {
+		return r.Name()
+	}
+	if strings.HasPrefix(r.path, officialRepoPrefix) && !strings.ContainsRune(r.path[len(officialRepoPrefix):], '/') {
+		return r.path[len(officialRepoPrefix):]
+	}
+	return r.path
+}
+
+// FamiliarString returns the reference the way it's displayed, like "ubuntu:latest".
+func (r Named) FamiliarString() string {
+	return r.FamiliarName() + r.suffix()
+}
+
+func (r Named) suffix() string {
+	var s string
+	if r.tag != "" {
+		s += ":" + r.tag
+	}
+	if r.digest != "" {
+		s += "@" + r.digest
+	}
+	return s
+}
+
+// WithDefaultTag returns the reference with the default tag
+// if it specifies neither a tag nor a digest.
+func (r Named) WithDefaultTag() Named {
+	if r.tag == "" && r.digest == "" {
+		r.tag = DefaultTag
+	}
+	return r
+}
@@ -0,0 +1,141 @@
+package reference
+
+import (
+	"testing"
+)
+
+const testDigest = "sha256:ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"
+
+func TestParseNormalizedNamed(t *testing.T) {
+	testCases := []struct {
+		ref            string
+		domain         string
+		path           string
+		tag            string
+		digest         string
+		normalized     string
+		familiarString string
+	}{
+		{
+			ref:            "ubuntu",
+			domain:         "docker.io",
+			path:           "library/ubuntu",
+			normalized:     "docker.io/library/ubuntu",
+			familiarString: "ubuntu",
+		},
+		{
+			ref:            "library/ubuntu:16.04",
+			domain:         "docker.io",
+			path:           "library/ubuntu",
+			tag:            "16.04",
+			normalized:     "docker.io/library/ubuntu:16.04",
+			familiarString: "ubuntu:16.04",
+		},
+		{
+			ref:            "index.docker.io/user/repo",
+			domain:         "docker.io",
+			path:           "user/repo",
+			normalized:     "docker.io/user/repo",
+			familiarString: "user/repo",
+		},
+		{
+			ref:            "docker.io/library/sub/repo",
+			domain:         "docker.io",
+			path:           "library/sub/repo",
+			normalized:     "docker.io/library/sub/repo",
+			familiarString: "library/sub/repo",
+		},
+		{
+			ref:            "localhost/repo:tag",
+			domain:         "localhost",
+			path:           "repo",
+			tag:            "tag",
+			normalized:     "localhost/repo:tag",
+			familiarString: "localhost/repo:tag",
+		},
+		{
+			ref:            "test.com:5000/user/repo@" + testDigest,
+			domain:         "test.com:5000",
+			path:           "user/repo",
+			digest:         testDigest,
+			normalized:     "test.com:5000/user/repo@" + testDigest,
+			familiarString: "test.com:5000/user/repo@" + testDigest,
+		},
+		{
+			ref:            "repo:tag@" + testDigest,
+			domain:         "docker.io",
+			path:           "library/repo",
+			tag:            "tag",
+			digest:         testDigest,
+			normalized:     "docker.io/library/repo:tag@" + testDigest,
+			familiarString: "repo:tag@" + testDigest,
+		},
+	}
+	for _, c := range testCases {
+		named, err := ParseNormalizedNamed(c.ref)
+		if err != nil {
+			t.Fatalf("error with %s: %v", c.ref, err)
+		}
+		if named.Domain() != c.domain || named.Path() != c.path || named.Tag() != c.tag || named.Digest() != c.digest {
+			t.Fatalf("expected %s to be parsed as %s, %s, %q and %q, got %s, %s, %q and %q", c.ref, c.domain, c.path, c.tag, c.digest, named.Domain(), named.Path(), named.Tag(), named.Digest())
+		}
+		if named.String() != c.normalized {
+			t.Fatalf("expected %s to be normalized as %s, got %s", c.ref, c.normalized, named.String())
+		}
+		if named.FamiliarString() != c.familiarString {
+			t.Fatalf("expected %s to be displayed as %s, got %s", c.ref, c.familiarString, named.FamiliarString())
+		}
+	}
+}
+
+func TestParseNormalizedNamedErrors(t *testing.T) {
+	testCases := []string{
+		"",
+		"Uppercase",
+		"repo:",
+		"aa/asdf$$^/aa",
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"repo@sha256:fff",
+		"repo@md5:ffffffffffffffffffffffffffffffff",
+	}
+	for _, ref := range testCases {
+		if _, err := ParseNormalizedNamed(ref); err == nil {
+			t.Fatalf("expected an error parsing %q", ref)
+		}
+	}
+}
+
+func TestWithDefaultTag(t *testing.T) {
+	testCases := map[string]string{
+		"ubuntu":                 "docker.io/library/ubuntu:latest",
+		"ubuntu:16.04":           "docker.io/library/ubuntu:16.04",
+		"ubuntu@" + testDigest:   "docker.io/library/ubuntu@" + testDigest,
+		"test.com/user/repo":     "test.com/user/repo:latest",
+		"test.com/user/repo:tag": "test.com/user/repo:tag",
+	}
+	for ref, expected := range testCases {
+		named, err := ParseNormalizedNamed(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := named.WithDefaultTag().String(); actual != expected {
+			t.Fatalf("expected %s to be tagged as %s, got %s", ref, expected, actual)
+		}
+	}
+}
+
+func TestValidateDigest(t *testing.T) {
+	testCases := map[string]bool{
+		testDigest: true,
+		"sha512:" + testDigest[7:] + testDigest[7:]: true,
+		"sha256:FFFF" + testDigest[11:]:             false,
+		"sha256:" + testDigest[8:]:                  false,
+		"sha1:ffff":                                 false,
+		"sha256":                                    false,
+	}
+	for digest, valid := range testCases {
+		if err := ValidateDigest(digest); (err == nil) != valid {
+			t.Fatalf("expected the digest %s to be valid: %v, got %v", digest, valid, err)
+		}
+	}
+}
@@ -2,7 +2,10 @@
 // consumers of the API stats endpoint.
 package types
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // ThrottlingData stores CPU throttling stats of one running container
 type ThrottlingData struct {
@@ -113,3 +116,121 @@ type StatsJSON struct {
 	// Networks request version >=1.21
 	Networks map[string]NetworkStats `json:"networks,omitempty"`
 }
+
+// StatsSummary holds the metrics derived from a stats sample,
+// like the ones the docker stats command displays.
+type StatsSummary struct {
+	// CPUPercentage is the percentage of the host's CPU used
+	// since the previous read, across all the cores.
+	CPUPercentage float64
+	// MemoryUsage is the memory used without the page cache.
+	MemoryUsage      uint64
+	MemoryLimit      uint64
+	MemoryPercentage float64
+	// NetworkRx and NetworkTx are the bytes received and sent
+	// through all the networks since the container started.
+	NetworkRx uint64
+	NetworkTx uint64
+	// NetworkRxDelta and NetworkTxDelta are the bytes received and sent
+	// since the previous sample.
+	NetworkRxDelta uint64
+	NetworkTxDelta uint64
+	// BlockRead and BlockWrite are the bytes read from and written
+	// to block devices since the container started.
+	BlockRead  uint64
+	BlockWrite uint64
+	// BlockReadDelta and BlockWriteDelta are the bytes read from and
+	// written to block devices since the previous sample.
+	BlockReadDelta  uint64
+	BlockWriteDelta uint64
+	// PidsCurrent is the number of processes in the container.
+	PidsCurrent uint64
+}
+
+// ContainerStatsSample holds a decoded stats sample
+// and the metrics derived from it.
+type ContainerStatsSample struct {
+	Stats   StatsJSON
+	Summary StatsSummary
+}
+
+// Summarize computes the metrics of the stats sample.
+// Deltas are computed against the previous sample,
+// they are zero when previous is nil.
+func (s *StatsJSON) Summarize(previous *StatsJSON) StatsSummary {
+	summary := StatsSummary{
+		CPUPercentage: cpuPercentage(s.PreCPUStats, s.CPUStats),
+		MemoryUsage:   s.MemoryStats.Usage,
+		MemoryLimit:   s.MemoryStats.Limit,
+		PidsCurrent:   s.PidsStats.Current,
+	}
+
+	if cache, ok := s.MemoryStats.Stats["cache"]; ok && cache < summary.MemoryUsage {
+		summary.MemoryUsage -= cache
+	}
+	if summary.MemoryLimit > 0 {
+		summary.MemoryPercentage = float64(summary.MemoryUsage) / float64(summary.MemoryLimit) * 100.0
+	}
+
+	summary.NetworkRx, summary.NetworkTx = s.networkTotals()
+	summary.BlockRead, summary.BlockWrite = s.blockTotals()
+
+	if previous != nil {
+		rx, tx := previous.networkTotals()
+		summary.NetworkRxDelta = counterDelta(rx, summary.NetworkRx)
+		summary.NetworkTxDelta = counterDelta(tx, summary.NetworkTx)
+
+		read, write := previous.blockTotals()
+		summary.BlockReadDelta = counterDelta(read, summary.BlockRead)
+		summary.BlockWriteDelta = counterDelta(write, summary.BlockWrite)
+	}
+	return summary
+}
+
+// networkTotals returns the bytes received and sent through all the networks.
+func (s *StatsJSON) networkTotals() (rx uint64, tx uint64) {
+	for _, n := range s.Networks {
+		rx += n.RxBytes
+		tx += n.TxBytes
+	}
+	return rx, tx
+}
+
+// blockTotals returns the bytes read from and written to all the block devices.
+func (s *StatsJSON) blockTotals() (read uint64, write uint64) {
+	for _, entry := range s.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			read += entry.Value
+		case "write":
+			write += entry.Value
+		}
+	}
+	return read, write
+}
+
+// cpuPercentage computes the CPU usage between two reads.
+// The usage of the container is relative to the usage of the whole
+// system, scaled by the number of cores.
+func cpuPercentage(previous, current CPUStats) float64 {
+	if current.CPUUsage.TotalUsage < previous.CPUUsage.TotalUsage || current.SystemUsage <= previous.SystemUsage {
+		return 0.0
+	}
+	cpuDelta := float64(current.CPUUsage.TotalUsage - previous.CPUUsage.TotalUsage)
+	systemDelta := float64(current.SystemUsage - previous.SystemUsage)
+
+	cores := len(current.CPUUsage.PercpuUsage)
+	if cores == 0 {
+		cores = 1
+	}
+	return cpuDelta / systemDelta * float64(cores) * 100.0
+}
+
+// counterDelta returns the increment of a counter,
+// counters that have been reset count from zero.
+func counterDelta(previous, current uint64) uint64 {
+	if current < previous {
+		return current
+	}
+	return current - previous
+}
@@ -96,6 +96,24 @@ type Image struct {
 	Size        int64
 	VirtualSize int64
 	Labels      map[string]string
+	// SharedSize is the size of the layers the image shares with other
+	// images. It's only computed by the disk usage: it's -1 otherwise,
+	// or 0 with the daemons that don't report it.
+	SharedSize int64
+	// Containers is the number of containers using the image. It's only
+	// computed by the disk usage: it's -1 otherwise, or 0 with the
+	// daemons that don't report it.
+	Containers int64
+}
+
+// UniqueSize returns the size of the layers of
+// the image that aren't shared with other images, or its
+// size when the shared size isn't computed.
+func (i Image) UniqueSize() int64 {
+	if i.SharedSize <= 0 {
+		return i.Size
+	}
+	return i.Size - i.SharedSize
 }
 
 // GraphDriverData returns Image's graph driver config info
@@ -189,11 +207,21 @@ type ContainerProcessList struct {
 	Titles    []string
 }
 
+// Ping contains response of Remote API:
+// GET "/_ping"
+type Ping struct {
+	// APIVersion is empty when the daemon is older than 1.25.
+	APIVersion   string
+	Experimental bool
+	OSType       string
+}
+
 // Version contains response of Remote API:
 // GET "/version"
 type Version struct {
 	Version       string
 	APIVersion    string `json:"ApiVersion"`
+	MinAPIVersion string `json:",omitempty"`
 	GitCommit     string
 	GoVersion     string
 	Os            string
@@ -429,6 +457,13 @@ type Volume struct {
 	Status     map[string]interface{} `json:",omitempty"` // Status provides low-level status information about the volume
 	Labels     map[string]string      // Labels is metadata specific to the volume
 	Scope      string                 // Scope describes the level at which the volume exists (e.g. `global` for cluster-wide or `local` for machine level)
+	UsageData  *VolumeUsageData       `json:",omitempty"` // UsageData is the usage of the volume, only computed by the disk usage
+}
+
+// VolumeUsageData is the usage of a volume.
+type VolumeUsageData struct {
+	Size     int64 // Size is the disk space used by the volume, or -1 if it's not available
+	RefCount int64 // RefCount is the number of containers referencing the volume, or -1 if it's not available
 }
 
 // VolumesListResponse contains the response for the remote API:
@@ -451,6 +486,7 @@ type VolumeCreateRequest struct {
 type NetworkResource struct {
 	Name       string                      // Name is the requested name of the network
 	ID         string                      `json:"Id"` // ID uniquely identifies a network on a single machine
+	Created    time.Time                   // Created is the time the network was created, daemons prior to API 1.25 don't report it
 	Scope      string                      // Scope describes the level at which the network exists (e.g. `global` for cluster-wide or `local` for machine level)
 	Driver     string                      // Driver is the Driver name used to create the network (e.g. `bridge`, `overlay`)
 	EnableIPv6 bool                        // EnableIPv6 represents whether to enable IPv6
@@ -517,3 +553,54 @@ type Runtime struct {
 	Path string   `json:"path"`
 	Args []string `json:"runtimeArgs,omitempty"`
 }
+
+// PushResult contains the tag, manifest digest, and manifest size from the
+// push. It's used to signal this information to the trust code in the client
+// so it can sign the manifest if necessary.
+type PushResult struct {
+	Tag    string
+	Digest string
+	Size   int
+}
+
+// BuildResult contains the image id of a successful build
+type BuildResult struct {
+	ID string
+}
+
+// ContainersPruneReport contains the response of Remote API:
+// POST "/containers/prune"
+type ContainersPruneReport struct {
+	ContainersDeleted []string
+	SpaceReclaimed    uint64
+}
+
+// ImagesPruneReport contains the response of Remote API:
+// POST "/images/prune"
+type ImagesPruneReport struct {
+	ImagesDeleted  []ImageDelete
+	SpaceReclaimed uint64
+}
+
+// VolumesPruneReport contains the response of Remote API:
+// POST "/volumes/prune"
+type VolumesPruneReport struct {
+	VolumesDeleted []string
+	SpaceReclaimed uint64
+}
+
+// NetworksPruneReport contains the response of Remote API:
+// POST "/networks/prune"
+type NetworksPruneReport struct {
+	NetworksDeleted []string
+}
+
+// DiskUsage contains the response of Remote API:
+// GET "/system/df"
+type DiskUsage struct {
+	LayersSize  int64
+	Images      []*Image
+	Containers  []*Container
+	Volumes     []*Volume
+	BuilderSize int64
+}